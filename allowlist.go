@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// readAllowlist reads the approved implementers from path. Every non empty line that doesn't start with #
+// is the qualified name of an approved type, like github.com/me/proj/pkg/aws.awsFetcher.
+func readAllowlist(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	allowed := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowed[line] = true
+	}
+	return allowed, scanner.Err()
+}
+
+// writeAllowlist replaces the content of path with the qualified names of strcts.
+func writeAllowlist(path string, strcts []inspector.Implementer) error {
+	names := make([]string, 0, len(strcts))
+	for _, strct := range strcts {
+		names = append(names, inspector.QualifiedName(strct.Obj))
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("# Types approved to implement the interface. Regenerate with -update-allowlist.\n")
+	for _, name := range names {
+		fmt.Fprintln(&sb, name)
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
+}
+
+// unapprovedImplementers returns the structs of strcts that aren't in allowed.
+func unapprovedImplementers(strcts []inspector.Implementer, allowed map[string]bool) []inspector.Implementer {
+	unapproved := make([]inspector.Implementer, 0)
+	for _, strct := range strcts {
+		if !allowed[inspector.QualifiedName(strct.Obj)] {
+			unapproved = append(unapproved, strct)
+		}
+	}
+	return unapproved
+}
+
+// printUnapproved writes an error line for every unapproved implementer.
+func printUnapproved(w io.Writer, unapproved []inspector.Implementer) {
+	for _, strct := range unapproved {
+		fmt.Fprintf(w, "error: unapproved implementer %s\n", strct.String())
+	}
+}
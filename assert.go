@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// checkAssertions checks every assertion and writes whether it holds. The methods a type lacks, or has with a wrong
+// signature, are listed under the assertion. holds is false if any assertion doesn't hold.
+func checkAssertions(w io.Writer, pkgs []*packages.Package, assertions []inspector.Assertion) (holds bool, err error) {
+	holds = true
+	for _, a := range assertions {
+		e, err := inspector.CheckAssertion(pkgs, a)
+		if err != nil {
+			return false, fmt.Errorf("%s: %v", a, err)
+		}
+		if e.Receiver != "" {
+			fmt.Fprintf(w, "ok   %s\n", a)
+			continue
+		}
+		holds = false
+		fmt.Fprintf(w, "FAIL %s\n", a)
+		printMissing(w, e.Missing)
+	}
+	return holds, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// batchResultJSON is the result of a query of -batch -format json, one per line.
+type batchResultJSON struct {
+	Query        string      `json:"query"`
+	Implementers []strctJSON `json:"implementers"`
+	Error        string      `json:"error,omitempty"`
+}
+
+// runBatch reads one query per line from r, a fully qualified interface optionally followed by the package
+// patterns to scan, like "github.com/me/proj/storage.Store ./internal/...", and writes the result of every
+// query as soon as it's done, see runQuery. All queries are answered from pkgs, which are loaded once.
+// Empty lines and lines starting with # are skipped. A query that can't be answered is reported and the next
+// one is read. With the json format, every result is a line of its own with the query, see batchResultJSON.
+// The query lines and the ok or FAIL lines of the formats other than text are written to status. passed is
+// false if a query failed.
+func runBatch(r io.Reader, w, status io.Writer, pkgs []*packages.Package, format string, structs func([]*packages.Package) []inspector.Struct) (passed bool, err error) {
+	passed = true
+	first := true
+	status = queryStatus(w, status, format)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(status)
+		}
+		first = false
+
+		fmt.Fprintf(status, "== %s\n", line)
+		fields := strings.Fields(line)
+		q := configQuery{Name: line, Interface: fields[0], Scan: fields[1:], Format: format}
+		if len(q.Scan) == 0 {
+			// everything that was loaded
+			q.Scan = []string{"..."}
+		}
+		var ok bool
+		if format == "json" {
+			ok, err = runBatchJSON(w, status, pkgs, q, structs)
+		} else {
+			ok, err = runQuery(w, status, pkgs, q, structs)
+		}
+		if err != nil {
+			fmt.Fprintf(status, "error: %s: %v\n", line, err)
+		}
+		passed = passed && ok
+	}
+	return passed, scanner.Err()
+}
+
+// runBatchJSON writes the result of q as a line of JSON to w, errors included, and ok or FAIL to status.
+// err is only set if the line can't be written.
+func runBatchJSON(w, status io.Writer, pkgs []*packages.Package, q configQuery, structs func([]*packages.Package) []inspector.Struct) (passed bool, err error) {
+	result := batchResultJSON{Query: q.Name}
+	iface, impls, err := findQueryImplementers(pkgs, q, structs)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Implementers = implementersToJSON(impls, iface)
+		passed = printQueryStatus(status, q, impls)
+	}
+	return passed, json.NewEncoder(w).Encode(result)
+}
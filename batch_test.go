@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+func TestRunBatchJSON(t *testing.T) {
+	pkgs := loadSelfTest(t)
+	structs := func(pkgs []*packages.Package) []inspector.Struct { return inspector.FindStructs(pkgs, 0) }
+	queries := "selftest/shapes.Solid\n# a comment\nselftest/shapes.Missing\nselftest/shapes.Polygon selftest/impl\n"
+
+	var stdout, stderr bytes.Buffer
+	passed, err := runBatch(strings.NewReader(queries), &stdout, &stderr, pkgs, "json", structs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passed {
+		t.Error("passed, want the query of the missing interface to fail")
+	}
+
+	// every line of stdout is the JSON of a query
+	want := []struct {
+		query, error string
+		implementers []string
+	}{
+		{"selftest/shapes.Solid", "", []string{"cube"}},
+		{"selftest/shapes.Missing", `no such interface "Missing" in package "selftest/shapes"`, nil},
+		{"selftest/shapes.Polygon selftest/impl", "", []string{"square", "rect", "cube"}},
+	}
+	scanner := bufio.NewScanner(&stdout)
+	for i := 0; scanner.Scan(); i++ {
+		var got batchResultJSON
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatalf("line %d isn't JSON: %v\n%s", i+1, err, scanner.Text())
+		}
+		if i >= len(want) {
+			t.Fatalf("unexpected line %s", scanner.Text())
+		}
+		gotNames := make([]string, 0)
+		for _, impl := range got.Implementers {
+			gotNames = append(gotNames, impl.Name)
+		}
+		if got.Query != want[i].query || got.Error != want[i].error || strings.Join(gotNames, ",") != strings.Join(want[i].implementers, ",") {
+			t.Errorf("line %d: got %+v, want %+v", i+1, got, want[i])
+		}
+	}
+	if !strings.Contains(stderr.String(), "== selftest/shapes.Solid\nok   selftest/shapes.Solid\n") {
+		t.Errorf("the status lines aren't in stderr: %q", stderr.String())
+	}
+}
@@ -0,0 +1,14 @@
+// The interfaceinspector command runs the interfaceinspector analyzer, on its own or as a vet tool:
+//
+//	interfaceinspector -require example.com/proj/storage=example.com/proj/storage.Repository ./...
+//	go vet -vettool=$(which interfaceinspector) -require ... -expect ... ./...
+package main
+
+import (
+	"github.com/magdyamr542/interface-inspector/interfaceinspector"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(interfaceinspector.Analyzer)
+}
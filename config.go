@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+	"gopkg.in/yaml.v3"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// defaultConfig is the config file -run reads without -config.
+const defaultConfig = ".interface-inspector.yaml"
+
+// config is the content of a config file, the named queries -run checks.
+type config struct {
+	Queries []configQuery `yaml:"queries"`
+}
+
+// configQuery is a query of a config file, like:
+//
+//	queries:
+//	  - name: stores
+//	    interface: github.com/me/proj/storage.Store
+//	    scan: [./internal/...]
+//	    exclude: [internal/mocks/...]
+//	    implementers: [github.com/me/proj/internal/postgres.Store]
+//	    format: text
+type configQuery struct {
+	Name string `yaml:"name"`
+	// Interface is the fully qualified interface, like -iface.
+	Interface string `yaml:"interface"`
+	// Scan are the package patterns to scan, like -scan. Defaults to ./...
+	Scan []string `yaml:"scan"`
+	// Exclude are globs of the packages not to scan, like -exclude.
+	Exclude []string `yaml:"exclude"`
+	// Implementers are the qualified names of the expected implementers. When set, the query fails if the
+	// implementers are different.
+	Implementers []string `yaml:"implementers"`
+	// Format is text (the default), json, grep or quickfix.
+	Format string `yaml:"format"`
+}
+
+// readConfig reads and checks the config file path.
+func readConfig(path string) (config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config{}, err
+	}
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return config{}, fmt.Errorf("parse %s: %v", path, err)
+	}
+	if len(cfg.Queries) == 0 {
+		return config{}, fmt.Errorf("%s has no queries", path)
+	}
+	for i, q := range cfg.Queries {
+		if q.Name == "" {
+			return config{}, fmt.Errorf("query %d of %s has no name", i+1, path)
+		}
+		if _, _, err := inspector.ParseRef(q.Interface); err != nil {
+			return config{}, fmt.Errorf("query %q: interface: %v", q.Name, err)
+		}
+		if len(q.Scan) == 0 {
+			cfg.Queries[i].Scan = []string{"./..."}
+		}
+		switch q.Format {
+		case "":
+			cfg.Queries[i].Format = "text"
+		case "text", "json", "grep", "quickfix":
+		default:
+			return config{}, fmt.Errorf("query %q: unknown format %q", q.Name, q.Format)
+		}
+	}
+	return cfg, nil
+}
+
+// runQueries loads the packages of all queries of cfg at once, with the options of query, and writes the
+// implementers of every query under its name. structs finds the types to check in the packages a query scans.
+// The names and the ok or FAIL lines of the queries with another format than text are written to status, so
+// that w only has the output of the format. passed is false if a query has no implementers or not the expected ones.
+func runQueries(w, status io.Writer, cfg config, query inspector.Query, structs func([]*packages.Package) []inspector.Struct) (passed bool, err error) {
+	query.Patterns = nil
+	seen := make(map[string]bool)
+	for _, q := range cfg.Queries {
+		pkgPath, _, _ := inspector.ParseRef(q.Interface)
+		for _, pattern := range append(append([]string(nil), q.Scan...), pkgPath) {
+			if !seen[pattern] {
+				seen[pattern] = true
+				query.Patterns = append(query.Patterns, pattern)
+			}
+		}
+	}
+	pkgs, err := inspector.Load(context.Background(), query)
+	if err != nil {
+		return false, err
+	}
+
+	passed = true
+	for i, q := range cfg.Queries {
+		qstatus := queryStatus(w, status, q.Format)
+		if i > 0 {
+			fmt.Fprintln(qstatus)
+		}
+		fmt.Fprintf(qstatus, "== %s: %s\n", q.Name, q.Interface)
+		ok, err := runQuery(w, qstatus, pkgs, q, structs)
+		if err != nil {
+			return false, fmt.Errorf("query %q: %v", q.Name, err)
+		}
+		passed = passed && ok
+	}
+	return passed, nil
+}
+
+// queryStatus returns the writer of the status lines of a query in format: w for text, status otherwise.
+func queryStatus(w, status io.Writer, format string) io.Writer {
+	if format == "text" {
+		return w
+	}
+	return status
+}
+
+// runQuery writes the implementers of q among pkgs to w, and ok or FAIL and the name of q to status,
+// see checkImplementers.
+func runQuery(w, status io.Writer, pkgs []*packages.Package, q configQuery, structs func([]*packages.Package) []inspector.Struct) (passed bool, err error) {
+	iface, impls, err := findQueryImplementers(pkgs, q, structs)
+	if err != nil {
+		return false, err
+	}
+
+	switch q.Format {
+	case "json":
+		err = printJSON(w, impls, iface)
+	case "grep", "quickfix":
+		printLocations(w, impls, nil, iface, q.Format == "quickfix")
+	default:
+		for _, impl := range impls {
+			fmt.Fprintln(w, impl.String())
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return printQueryStatus(status, q, impls), nil
+}
+
+// findQueryImplementers finds the interface of q and its implementers among the packages of pkgs q scans.
+func findQueryImplementers(pkgs []*packages.Package, q configQuery, structs func([]*packages.Package) []inspector.Struct) (inspector.Interface, []inspector.Implementer, error) {
+	iface, err := inspector.FindInterfaceByRef(pkgs, q.Interface)
+	if err != nil {
+		return inspector.Interface{}, nil, err
+	}
+	pkgPath, _, _ := inspector.ParseRef(q.Interface)
+	scanPkgs, err := inspector.FilterPatternPackages(inspector.DropExternalPackage(pkgs, pkgPath), ".", q.Scan)
+	if err == nil {
+		scanPkgs, err = inspector.FilterExcludedPackages(scanPkgs, ".", q.Exclude)
+	}
+	if err != nil {
+		return inspector.Interface{}, nil, err
+	}
+	return iface, inspector.Implementers(structs(scanPkgs), iface), nil
+}
+
+// printQueryStatus writes ok or FAIL and the name of q, followed by the problems of impls, and returns whether
+// the query passed.
+func printQueryStatus(w io.Writer, q configQuery, impls []inspector.Implementer) bool {
+	problems := checkImplementers(impls, q.Implementers)
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "FAIL %s\n", q.Name)
+		for _, p := range problems {
+			fmt.Fprintf(w, "\t%s\n", p)
+		}
+		return false
+	}
+	fmt.Fprintf(w, "ok   %s\n", q.Name)
+	return true
+}
+
+// checkImplementers lists what's wrong with impls: that there are none or, with expected, the implementers that
+// aren't expected and the expected ones that are missing.
+func checkImplementers(impls []inspector.Implementer, expected []string) []string {
+	if len(expected) == 0 {
+		if len(impls) == 0 {
+			return []string{"no implementers"}
+		}
+		return nil
+	}
+
+	problems := make([]string, 0)
+	found := make(map[string]bool, len(impls))
+	for _, impl := range impls {
+		name := inspector.QualifiedName(impl.Obj)
+		found[name] = true
+		if !contains(expected, name) {
+			problems = append(problems, "unexpected implementer "+name)
+		}
+	}
+	missing := make([]string, 0)
+	for _, name := range expected {
+		if !found[name] {
+			missing = append(missing, "missing implementer "+name)
+		}
+	}
+	sort.Strings(missing)
+	return append(problems, missing...)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
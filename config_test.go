@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+func TestRunQueriesStatus(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeSelfTestModule(dir); err != nil {
+		t.Fatal(err)
+	}
+	structs := func(pkgs []*packages.Package) []inspector.Struct { return inspector.FindStructs(pkgs, 0) }
+	query := func(format string) configQuery {
+		return configQuery{Name: "solids", Interface: "selftest/shapes.Solid", Scan: []string{"selftest/..."}, Format: format}
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		passed, err := runQueries(&stdout, &stderr, config{Queries: []configQuery{query("json")}}, inspector.Query{Dir: dir}, structs)
+		if err != nil || !passed {
+			t.Fatalf("got %v, %v, want the query to pass", passed, err)
+		}
+		// stdout is only the JSON
+		var impls []strctJSON
+		if err := json.Unmarshal(stdout.Bytes(), &impls); err != nil {
+			t.Fatalf("stdout isn't JSON: %v\n%s", err, stdout.String())
+		}
+		if len(impls) != 1 || impls[0].Name != "cube" {
+			t.Errorf("got %+v, want cube", impls)
+		}
+		if want := "== solids: selftest/shapes.Solid\nok   solids\n"; stderr.String() != want {
+			t.Errorf("got stderr %q, want %q", stderr.String(), want)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		passed, err := runQueries(&stdout, &stderr, config{Queries: []configQuery{query("text")}}, inspector.Query{Dir: dir}, structs)
+		if err != nil || !passed {
+			t.Fatalf("got %v, %v, want the query to pass", passed, err)
+		}
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		if len(lines) != 3 || lines[0] != "== solids: selftest/shapes.Solid" || !strings.HasPrefix(lines[1], "cube ") || lines[2] != "ok   solids" {
+			t.Errorf("got stdout %q", stdout.String())
+		}
+		if stderr.Len() > 0 {
+			t.Errorf("got stderr %q, want nothing", stderr.String())
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// checkoutRevision checks out rev in a temporary git worktree and returns the directory in it that corresponds to
+// the current directory. remove deletes the worktree.
+func checkoutRevision(rev string) (dir string, remove func(), err error) {
+	top, err := git(".", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	// the top level is resolved by git, so the working directory is too before comparing them
+	if resolved, err := filepath.EvalSymlinks(wd); err == nil {
+		wd = resolved
+	}
+	rel, err := filepath.Rel(top, wd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "interface-inspector-base")
+	if err != nil {
+		return "", nil, err
+	}
+	worktree := filepath.Join(tmpDir, "worktree")
+	if _, err := git(".", "worktree", "add", "--detach", worktree, rev); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, err
+	}
+	remove = func() {
+		git(".", "worktree", "remove", "--force", worktree)
+		os.RemoveAll(tmpDir)
+	}
+	return filepath.Join(worktree, rel), remove, nil
+}
+
+// git runs git with args in dir and returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// printDiff writes the added implementers prefixed with +, the removed ones with -, the ones whose receiver changed
+// with ~ and the broken ones with ! followed by what they lack. The positions of the old revision, checked out in
+// baseDir, are written relative to it and prefixed with rev.
+func printDiff(w io.Writer, diff inspector.ImplementerDiff, iface inspector.Interface, rev, baseDir string) {
+	for _, impl := range diff.Added {
+		fmt.Fprintf(w, "+ %s\n", impl.String())
+	}
+	for _, impl := range diff.Removed {
+		position := impl.PositionString()
+		if rel, err := filepath.Rel(baseDir, impl.Position.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+			position = fmt.Sprintf("%s:%s:%d:%d", rev, filepath.ToSlash(rel), impl.Position.Line, impl.Position.Column)
+		}
+		fmt.Fprintf(w, "- %s %s\n", impl.Label(), position)
+	}
+	for _, impl := range diff.ReceiverChanged {
+		fmt.Fprintf(w, "~ %s\n", impl.String())
+	}
+	for _, e := range diff.Broken {
+		fmt.Fprintf(w, "! %s doesn't implement %s.%s anymore\n", e.String(), iface.Pkg.Name(), iface.Name)
+		printMissing(w, e.Missing)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// TestDiffExcluded compares a revision with itself while mocks is excluded and gen is ignored. The directories
+// of the base revision, checked out elsewhere, are excluded too, so nothing is reported as removed.
+func TestDiffExcluded(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":     "module example.com/m\n\ngo 1.22\n",
+		".gitignore": "gen/\n",
+		"db/db.go":   "package db\n\ntype Store interface{ Get() }\n\ntype PG struct{}\n\nfunc (PG) Get() {}\n",
+		"mocks/m.go": "package mocks\n\ntype MockStore struct{}\n\nfunc (MockStore) Get() {}\n",
+		"gen/g.go":   "package gen\n\ntype GenStore struct{}\n\nfunc (GenStore) Get() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-f", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "base"},
+	} {
+		if _, err := git(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// checkoutRevision works from the current directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	filter := packageFilter{excludes: []string{"mocks/..."}, gitignore: true}
+	implementers := func(root string) ([]inspector.Struct, []inspector.Implementer, inspector.Interface) {
+		t.Helper()
+		pkgs := load(t, inspector.Query{Dir: root})
+		scan, err := filter.scanPackages(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		iface, err := inspector.FindInterfaceByRef(pkgs, "example.com/m/db.Store")
+		if err != nil {
+			t.Fatal(err)
+		}
+		strcts := inspector.FindStructs(scan(pkgs), 0)
+		return strcts, inspector.Implementers(strcts, iface), iface
+	}
+
+	strcts, impls, iface := implementers(".")
+	baseDir, remove, err := checkoutRevision("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remove()
+	_, baseImpls, _ := implementers(baseDir)
+
+	for _, side := range [][]inspector.Implementer{impls, baseImpls} {
+		if len(side) != 1 || side[0].Name != "PG" {
+			t.Fatalf("got implementers %v, want PG only", side)
+		}
+	}
+	var out bytes.Buffer
+	printDiff(&out, inspector.DiffImplementers(baseImpls, impls, strcts, iface), iface, "HEAD", baseDir)
+	if out.Len() > 0 {
+		t.Errorf("got a diff, want none:\n%s", out.String())
+	}
+}
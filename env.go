@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// envInfo describes the environment a scan ran in, for bug reports.
+type envInfo struct {
+	GoVersion     string   `json:"go_version"`
+	Module        string   `json:"module"`
+	BuildTags     []string `json:"build_tags"`
+	GOOS          string   `json:"goos"`
+	GOARCH        string   `json:"goarch"`
+	Packages      int      `json:"packages"`
+	TotalPackages int      `json:"total_packages"`
+}
+
+// collectEnv queries the go command, run with env, for its version and target platform and counts the loaded packages.
+// Packages are the ones matched by the patterns, TotalPackages includes their dependencies.
+func collectEnv(pkgs []*packages.Package, buildTags []string, env []string) (envInfo, error) {
+	cmd := exec.Command("go", "env", "-json", "GOVERSION", "GOOS", "GOARCH")
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return envInfo{}, fmt.Errorf("go env: %w", err)
+	}
+	var goEnv map[string]string
+	if err := json.Unmarshal(out, &goEnv); err != nil {
+		return envInfo{}, fmt.Errorf("go env: %w", err)
+	}
+
+	info := envInfo{
+		GoVersion: goEnv["GOVERSION"],
+		BuildTags: buildTags,
+		GOOS:      goEnv["GOOS"],
+		GOARCH:    goEnv["GOARCH"],
+		Packages:  len(pkgs),
+	}
+	if info.BuildTags == nil {
+		info.BuildTags = []string{}
+	}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		info.TotalPackages++
+		if pkg.Module != nil && pkg.Module.Main && info.Module == "" {
+			info.Module = pkg.Module.Path
+		}
+	})
+	return info, nil
+}
+
+func printEnv(w io.Writer, info envInfo, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	fmt.Fprintf(w, "go_version: %s\n", info.GoVersion)
+	fmt.Fprintf(w, "module: %s\n", info.Module)
+	fmt.Fprintf(w, "build_tags: %s\n", strings.Join(info.BuildTags, ","))
+	fmt.Fprintf(w, "goos: %s\n", info.GOOS)
+	fmt.Fprintf(w, "goarch: %s\n", info.GOARCH)
+	fmt.Fprintf(w, "packages: %d\n", info.Packages)
+	fmt.Fprintf(w, "total_packages: %d\n", info.TotalPackages)
+	return nil
+}
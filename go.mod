@@ -1,10 +1,13 @@
 module github.com/magdyamr542/interface-inspector
 
-go 1.19
-
-require golang.org/x/tools v0.6.0
+go 1.22.0
 
 require (
-	golang.org/x/mod v0.8.0 // indirect
-	golang.org/x/sys v0.5.0 // indirect
+	github.com/fsnotify/fsnotify v1.8.0
+	golang.org/x/mod v0.22.0
+	golang.org/x/sys v0.28.0
+	golang.org/x/tools v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sync v0.10.0 // indirect
@@ -0,0 +1,57 @@
+package inspector
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Assertion claims that a type implements an interface. Both are references like pkg/db.Store, see FindStructByRef.
+type Assertion struct {
+	Type      string
+	Interface string
+}
+
+func (a Assertion) String() string {
+	return a.Type + " implements " + a.Interface
+}
+
+// ParseAssertion parses an assertion written like "pkg/db.PostgresStore implements pkg/db.Store".
+func ParseAssertion(s string) (Assertion, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 || fields[1] != "implements" {
+		return Assertion{}, fmt.Errorf("invalid assertion %q, expected something like %q", s, "pkg/db.PostgresStore implements pkg/db.Store")
+	}
+	for _, ref := range []string{fields[0], fields[2]} {
+		if _, _, err := ParseRef(ref); err != nil {
+			return Assertion{}, err
+		}
+	}
+	return Assertion{Type: fields[0], Interface: fields[2]}, nil
+}
+
+// CheckAssertion looks up the type and the interface of a in pkgs and their dependencies and explains whether the type
+// implements the interface. The assertion holds if the Receiver of the explanation is set, that is if the type or
+// a pointer to it implements the interface.
+func CheckAssertion(pkgs []*packages.Package, a Assertion) (Explanation, error) {
+	strct, err := FindStructByRef(pkgs, a.Type)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	pkgPath, interfaceName, err := ParseRef(a.Interface)
+	if err != nil {
+		return Explanation{}, err
+	}
+	thePackage, err := matchPackage(pkgs, pkgPath, interfaceName)
+	if err != nil {
+		return Explanation{}, err
+	}
+	iface, err := lookupInterface(thePackage, interfaceName, thePackage.PkgPath)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	return Explain(strct, iface), nil
+}
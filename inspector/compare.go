@@ -0,0 +1,110 @@
+package inspector
+
+import (
+	"go/types"
+)
+
+// Relation tells how the method sets of two interfaces relate.
+type Relation string
+
+const (
+	Identical Relation = "identical"
+	// Subset means every method of the first interface is a method of the second, which has more.
+	// The second interface is then assignable to the first.
+	Subset      Relation = "subset"
+	Superset    Relation = "superset"
+	Overlapping Relation = "overlapping"
+	Disjoint    Relation = "disjoint"
+)
+
+// Comparison is the result of comparing the method sets of two interfaces A and B.
+type Comparison struct {
+	A, B     Interface
+	Relation Relation
+	// Common are the methods of A that B has with the same signature.
+	Common []*types.Func
+	OnlyA  []*types.Func
+	OnlyB  []*types.Func
+	// Different are the methods of A that B has with another signature, which is Have.
+	Different []MethodMatch
+	// Implementers are the types implementing both interfaces, with the receiver needed for both.
+	Implementers []Implementer
+}
+
+// CompareInterfaces compares the method sets of a and b and finds the types of strcts implementing both.
+func CompareInterfaces(a, b Interface, strcts []Struct) Comparison {
+	c := Comparison{A: a, B: b}
+	for i := 0; i < a.Type.NumMethods(); i++ {
+		m := a.Type.Method(i)
+		obj, _, _ := types.LookupFieldOrMethod(b.Type, false, m.Pkg(), m.Name())
+		other, ok := obj.(*types.Func)
+		switch {
+		case ok && types.Identical(m.Type(), other.Type()):
+			c.Common = append(c.Common, m)
+		case ok:
+			c.Different = append(c.Different, MethodMatch{Method: m, WrongSignature: true, Have: other})
+		default:
+			c.OnlyA = append(c.OnlyA, m)
+		}
+	}
+	for i := 0; i < b.Type.NumMethods(); i++ {
+		m := b.Type.Method(i)
+		if obj, _, _ := types.LookupFieldOrMethod(a.Type, false, m.Pkg(), m.Name()); obj == nil {
+			c.OnlyB = append(c.OnlyB, m)
+		}
+	}
+
+	differs := len(c.Different) > 0
+	switch {
+	case !differs && len(c.OnlyA) == 0 && len(c.OnlyB) == 0:
+		c.Relation = Identical
+	case !differs && len(c.OnlyA) == 0:
+		c.Relation = Subset
+	case !differs && len(c.OnlyB) == 0:
+		c.Relation = Superset
+	case len(c.Common) > 0 || differs:
+		c.Relation = Overlapping
+	default:
+		c.Relation = Disjoint
+	}
+
+	implementsB := make(map[*types.TypeName]ReceiverKind)
+	for _, impl := range Implementers(strcts, b) {
+		implementsB[impl.Obj] = impl.Receiver
+	}
+	for _, impl := range Implementers(strcts, a) {
+		receiver, ok := implementsB[impl.Obj]
+		if !ok {
+			continue
+		}
+		if receiver == PointerReceiver {
+			impl.Receiver = PointerReceiver
+		}
+		c.Implementers = append(c.Implementers, impl)
+	}
+	return c
+}
+
+// FindDuplicateInterfaces groups the interfaces of ifaces with identical method sets, which are likely
+// duplicate abstractions. Only groups of at least two interfaces are returned, in the order of ifaces.
+// Empty interfaces are left out.
+func FindDuplicateInterfaces(ifaces []Interface) [][]Interface {
+	groups := make([][]Interface, 0)
+	grouped := make([]bool, len(ifaces))
+	for i, iface := range ifaces {
+		if grouped[i] || iface.Type.Empty() {
+			continue
+		}
+		group := []Interface{iface}
+		for j := i + 1; j < len(ifaces); j++ {
+			if !grouped[j] && types.Identical(iface.Type, ifaces[j].Type) {
+				group = append(group, ifaces[j])
+				grouped[j] = true
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,70 @@
+package inspector
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ConformanceStatus tells how close a type is to implementing an interface.
+type ConformanceStatus string
+
+const (
+	Conforms    ConformanceStatus = "conforms"
+	NearMissing ConformanceStatus = "near-miss"
+	Unrelated   ConformanceStatus = "unrelated"
+)
+
+// Conformance is the result of checking one exported type of a package against an interface.
+type Conformance struct {
+	Obj      *types.TypeName
+	Position token.Position
+	Status   ConformanceStatus
+	// Missing is only set for near misses.
+	Missing []MethodMatch
+}
+
+// CheckPackageConformance checks every exported, non interface type declared in the package with the import path
+// pkgPath against iface. Types having some but not all of the methods, see IsNearMiss, are near misses.
+func CheckPackageConformance(pkgs []*packages.Package, pkgPath string, iface Interface) ([]Conformance, error) {
+	var thePackage *packages.Package
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == pkgPath {
+			thePackage = pkg
+			break
+		}
+	}
+	if thePackage == nil {
+		return nil, fmt.Errorf("couldn't find a package with the import path %q", pkgPath)
+	}
+	if len(thePackage.Errors) > 0 {
+		return nil, fmt.Errorf("load %q: %v", pkgPath, thePackage.Errors[0])
+	}
+
+	result := make([]Conformance, 0)
+	scope := thePackage.Types.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() || !obj.Exported() {
+			continue
+		}
+		if _, ok := obj.Type().Underlying().(*types.Interface); ok {
+			continue
+		}
+
+		c := Conformance{Obj: obj, Position: thePackage.Fset.Position(obj.Pos()), Status: Unrelated}
+		missing, implemented := MatchMethods(PointerTo(obj.Type()), iface.Type)
+		switch {
+		case len(missing) == 0:
+			c.Status = Conforms
+		case IsNearMiss(missing, implemented):
+			c.Status = NearMissing
+			c.Missing = missing
+		}
+		result = append(result, c)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package inspector
+
+import (
+	"golang.org/x/tools/go/packages"
+)
+
+// DeadInterface is an interface without implementers or without usages.
+type DeadInterface struct {
+	Interface
+	Implementers int
+	// Usages doesn't count the compile time implements checks, see UsageImplementsCheck.
+	Usages int
+}
+
+// FindDeadInterfaces returns the interfaces of ifaces that no type of strcts implements, or that aren't used
+// in the syntax of pkgs but by implements checks. Empty interfaces are left out since every type implements them.
+func FindDeadInterfaces(pkgs []*packages.Package, strcts []Struct, ifaces []Interface) []DeadInterface {
+	usages := make(map[string]int)
+	for _, u := range FindUsages(pkgs, ifaces) {
+		if u.Kind != UsageImplementsCheck {
+			usages[QualifiedName(u.Obj)]++
+		}
+	}
+
+	dead := make([]DeadInterface, 0)
+	for _, iface := range ifaces {
+		if iface.Type.Empty() {
+			continue
+		}
+		d := DeadInterface{
+			Interface:    iface,
+			Implementers: len(Implementers(strcts, iface)),
+			Usages:       usages[iface.QualifiedName()],
+		}
+		if d.Implementers == 0 || d.Usages == 0 {
+			dead = append(dead, d)
+		}
+	}
+	return dead
+}
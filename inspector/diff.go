@@ -0,0 +1,55 @@
+package inspector
+
+// ImplementerDiff is the difference between the implementers of an interface in two revisions of the code.
+type ImplementerDiff struct {
+	// Added are the implementers that only implement the interface in the new revision.
+	Added []Implementer
+	// Removed are the implementers of the old revision whose type doesn't exist anymore.
+	Removed []Implementer
+	// Broken are the implementers of the old revision whose type still exists but doesn't implement the interface
+	// anymore, explained against the new revision.
+	Broken []Explanation
+	// ReceiverChanged are the implementers that implement the interface with another receiver than before,
+	// like a value whose methods moved to pointer receivers.
+	ReceiverChanged []Implementer
+}
+
+// DiffImplementers compares the implementers of iface, oldImpls in the old revision and newImpls in the new one.
+// Types are matched on their qualified names. newStructs are the types of the new revision, which tell the removed
+// implementers from the broken ones.
+func DiffImplementers(oldImpls, newImpls []Implementer, newStructs []Struct, iface Interface) ImplementerDiff {
+	var diff ImplementerDiff
+	old := make(map[string]Implementer, len(oldImpls))
+	for _, impl := range oldImpls {
+		old[QualifiedName(impl.Obj)] = impl
+	}
+	current := make(map[string]bool, len(newImpls))
+	for _, impl := range newImpls {
+		name := QualifiedName(impl.Obj)
+		current[name] = true
+		before, ok := old[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, impl)
+		case before.Receiver != impl.Receiver:
+			diff.ReceiverChanged = append(diff.ReceiverChanged, impl)
+		}
+	}
+
+	strcts := make(map[string]Struct, len(newStructs))
+	for _, strct := range newStructs {
+		strcts[QualifiedName(strct.Obj)] = strct
+	}
+	for _, impl := range oldImpls {
+		name := QualifiedName(impl.Obj)
+		if current[name] {
+			continue
+		}
+		if strct, ok := strcts[name]; ok {
+			diff.Broken = append(diff.Broken, Explain(strct, iface))
+		} else {
+			diff.Removed = append(diff.Removed, impl)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,142 @@
+package inspector
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Origin tells where a package comes from.
+type Origin string
+
+const (
+	OriginModule     Origin = "module"
+	OriginStdlib     Origin = "stdlib"
+	OriginThirdParty Origin = "third-party"
+)
+
+// EmbeddingNode is an interface in the embedding tree of an interface, see EmbeddingTree.
+type EmbeddingNode struct {
+	// Name is the name of the interface qualified by its package name, like io.Reader, or the type itself
+	// for embedded interface literals.
+	Name string
+	// Origin is empty for interface literals.
+	Origin Origin
+	// Methods are the methods declared by the interface itself, the others come from Embedded.
+	Methods  []*types.Func
+	Embedded []EmbeddingNode
+}
+
+// EmbeddingTree returns iface with the interfaces it embeds, directly or not, and the methods each of them
+// contributes. pkgs tell whether the packages of the interfaces belong to the main module, the standard library
+// or another module.
+func EmbeddingTree(pkgs []*packages.Package, iface Interface) EmbeddingNode {
+	t := iface.Type
+	if iface.Named != nil {
+		t = iface.Named.Underlying().(*types.Interface)
+	}
+	modules := packageModules(pkgs)
+	root := embeddingNode(t, modules)
+	root.Name = iface.Pkg.Name() + "." + iface.Name
+	root.Origin = origin(iface.Pkg.Path(), modules)
+	return root
+}
+
+func embeddingNode(t *types.Interface, modules map[string]*packages.Module) EmbeddingNode {
+	var node EmbeddingNode
+	for i := 0; i < t.NumExplicitMethods(); i++ {
+		node.Methods = append(node.Methods, t.ExplicitMethod(i))
+	}
+	for i := 0; i < t.NumEmbeddeds(); i++ {
+		embedded := types.Unalias(t.EmbeddedType(i))
+		iface, ok := embedded.Underlying().(*types.Interface)
+		if !ok {
+			// a type set, like ~int | ~string in a constraint
+			node.Embedded = append(node.Embedded, EmbeddingNode{Name: types.TypeString(embedded, PackageNameQualifier)})
+			continue
+		}
+		child := embeddingNode(iface, modules)
+		child.Name = types.TypeString(embedded, PackageNameQualifier)
+		if named, ok := embedded.(*types.Named); ok && named.Obj().Pkg() != nil {
+			child.Origin = origin(named.Obj().Pkg().Path(), modules)
+		}
+		node.Embedded = append(node.Embedded, child)
+	}
+	return node
+}
+
+// packageModules maps the import paths of pkgs and their dependencies to their modules.
+func packageModules(pkgs []*packages.Package) map[string]*packages.Module {
+	modules := make(map[string]*packages.Module)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		modules[pkg.PkgPath] = pkg.Module
+	})
+	return modules
+}
+
+func origin(pkgPath string, modules map[string]*packages.Module) Origin {
+	module := modules[pkgPath]
+	switch {
+	case module != nil && module.Main:
+		return OriginModule
+	case module != nil:
+		return OriginThirdParty
+	}
+	// like StdlibPackages, the first element of the import paths of the standard library has no dot
+	if first, _, _ := strings.Cut(pkgPath, "/"); !strings.Contains(first, ".") {
+		return OriginStdlib
+	}
+	return OriginThirdParty
+}
+
+// Embedder is an interface that embeds another one.
+type Embedder struct {
+	Interface
+	// Via are the interfaces, qualified by their package name, the other one is embedded through.
+	// It's empty if it's embedded directly.
+	Via []string
+}
+
+// FindEmbedders returns the interfaces of ifaces that embed target, directly or through other interfaces.
+func FindEmbedders(ifaces []Interface, target Interface) []Embedder {
+	if target.Named == nil {
+		return nil
+	}
+	targetName := QualifiedName(target.Named.Obj())
+
+	embedders := make([]Embedder, 0)
+	for _, iface := range ifaces {
+		if iface.Named == nil || QualifiedName(iface.Named.Obj()) == targetName {
+			continue
+		}
+		if via, ok := embeddingPath(iface.Named.Underlying().(*types.Interface), targetName, nil); ok {
+			embedders = append(embedders, Embedder{Interface: iface, Via: via})
+		}
+	}
+	return embedders
+}
+
+// embeddingPath looks for the interface named targetName among the embeddings of t, depth first,
+// and returns the named interfaces in between, after the ones of prefix, those between the embedder and t.
+func embeddingPath(t *types.Interface, targetName string, prefix []string) ([]string, bool) {
+	for i := 0; i < t.NumEmbeddeds(); i++ {
+		embedded := types.Unalias(t.EmbeddedType(i))
+		iface, ok := embedded.Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		named, isNamed := embedded.(*types.Named)
+		if isNamed && QualifiedName(named.Obj()) == targetName {
+			return prefix, true
+		}
+		via := prefix
+		if isNamed {
+			via = append(append([]string(nil), prefix...), types.TypeString(named, PackageNameQualifier))
+		}
+		if path, ok := embeddingPath(iface, targetName, via); ok {
+			return path, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,34 @@
+package inspector
+
+import (
+	"regexp"
+)
+
+// StructFilter selects the types worth reporting, like the ones that aren't mocks. The zero value keeps them all.
+type StructFilter struct {
+	// ExportedOnly keeps the exported types only.
+	ExportedOnly bool
+	// Package keeps the types whose package import path matches it.
+	Package *regexp.Regexp
+	// Name keeps the types whose name matches it, and ExcludeName drops them. The names are matched without
+	// type parameters or enclosing function.
+	Name        *regexp.Regexp
+	ExcludeName *regexp.Regexp
+}
+
+// FilterStructs keeps the types of strcts that f selects.
+func FilterStructs(strcts []Struct, f StructFilter) []Struct {
+	kept := make([]Struct, 0, len(strcts))
+	for _, strct := range strcts {
+		name := strct.Obj.Name()
+		switch {
+		case f.ExportedOnly && !strct.Obj.Exported():
+		case f.Package != nil && !f.Package.MatchString(strct.PkgPath):
+		case f.Name != nil && !f.Name.MatchString(name):
+		case f.ExcludeName != nil && f.ExcludeName.MatchString(name):
+		default:
+			kept = append(kept, strct)
+		}
+	}
+	return kept
+}
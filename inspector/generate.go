@@ -0,0 +1,117 @@
+package inspector
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Stubs are the method stubs a type needs to implement an interface.
+type Stubs struct {
+	// Source is the formatted source of the stubs, one method per missing method of the interface.
+	// Methods the type has with a wrong signature aren't stubbed, since they would clash, but listed in a comment.
+	Source []byte
+	// Imports are the import paths of the packages the stubs refer to, other than the one of the type.
+	Imports []string
+}
+
+// GenerateStubs writes a method with a panic("unimplemented") body for every method of iface strct lacks.
+// The receiver name and kind, value or pointer, follow the methods strct already has. Without methods,
+// structs get a pointer receiver and other types a value receiver. The Source is empty if strct implements iface.
+func GenerateStubs(strct Struct, iface Interface) (Stubs, error) {
+	named, ok := strct.Obj.Type().(*types.Named)
+	if !ok {
+		return Stubs{}, fmt.Errorf("%s isn't a defined type and can't have methods", strct.Name)
+	}
+
+	pkg := strct.Obj.Pkg()
+	imports := make(map[string]bool)
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		imports[p.Path()] = true
+		return p.Name()
+	}
+
+	recvName, pointer := stubReceiver(named)
+	recvType := strct.Obj.Name()
+	if tparams := named.TypeParams(); tparams.Len() > 0 {
+		names := make([]string, tparams.Len())
+		for i := range names {
+			names[i] = tparams.At(i).Obj().Name()
+		}
+		recvType += "[" + strings.Join(names, ", ") + "]"
+	}
+	if pointer {
+		recvType = "*" + recvType
+	}
+
+	explanation := Explain(strct, iface)
+	if len(explanation.Missing) == 0 {
+		return Stubs{}, nil
+	}
+
+	var buf bytes.Buffer
+	ifaceName := iface.Pkg.Name() + "." + iface.Name
+	for _, m := range explanation.Missing {
+		if m.WrongSignature {
+			fmt.Fprintf(&buf, "// %s has a wrong signature: have %s, want %s\n\n", m.Method.Name(),
+				types.TypeString(m.Have.Type(), qualifier), types.TypeString(m.Method.Type(), qualifier))
+			continue
+		}
+		sig := m.Method.Type().(*types.Signature)
+		fmt.Fprintf(&buf, "// %s implements %s.\n", m.Method.Name(), ifaceName)
+		fmt.Fprintf(&buf, "func (%s %s) %s", stubReceiverName(recvName, sig), recvType, m.Method.Name())
+		types.WriteSignature(&buf, sig, qualifier)
+		buf.WriteString(" {\n\tpanic(\"unimplemented\")\n}\n\n")
+	}
+
+	src, err := format.Source(bytes.TrimSpace(buf.Bytes()))
+	if err != nil {
+		return Stubs{}, fmt.Errorf("format stubs: %v", err)
+	}
+	stubs := Stubs{Source: src}
+	for path := range imports {
+		stubs.Imports = append(stubs.Imports, path)
+	}
+	sort.Strings(stubs.Imports)
+	return stubs, nil
+}
+
+// stubReceiver returns the receiver name and kind of the first method of named that has a named receiver.
+func stubReceiver(named *types.Named) (string, bool) {
+	_, pointer := named.Underlying().(*types.Struct)
+	for i := 0; i < named.NumMethods(); i++ {
+		recv := named.Method(i).Type().(*types.Signature).Recv()
+		_, pointer = recv.Type().(*types.Pointer)
+		if recv.Name() != "" && recv.Name() != "_" {
+			return recv.Name(), pointer
+		}
+	}
+	first := []rune(named.Obj().Name())[0]
+	return string(unicode.ToLower(first)), pointer
+}
+
+// stubReceiverName returns name, or name with an underscore appended as long as a parameter or result of sig
+// has the same name.
+func stubReceiverName(name string, sig *types.Signature) string {
+	taken := func(name string) bool {
+		for _, vars := range []*types.Tuple{sig.Params(), sig.Results()} {
+			for i := 0; i < vars.Len(); i++ {
+				if vars.At(i).Name() == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for taken(name) {
+		name += "_"
+	}
+	return name
+}
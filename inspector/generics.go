@@ -0,0 +1,230 @@
+package inspector
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Instantiate instantiates the generic interface iface with typeArgs, a comma separated list of type expressions
+// like "string, int". The expressions are evaluated in the scope of the interface's package, so they may refer to
+// predeclared types and to the types declared in that package.
+func Instantiate(iface Interface, typeArgs string) (Interface, error) {
+	if iface.TypeParams.Len() == 0 {
+		return Interface{}, fmt.Errorf("interface %q isn't generic", iface.Name)
+	}
+
+	tv, err := types.Eval(token.NewFileSet(), iface.Pkg, token.NoPos, iface.Name+"["+typeArgs+"]")
+	if err != nil {
+		return Interface{}, err
+	}
+	named, ok := tv.Type.(*types.Named)
+	if !ok || !tv.IsType() {
+		return Interface{}, fmt.Errorf("%s[%s] isn't a type", iface.Name, typeArgs)
+	}
+
+	instance := iface
+	instance.Type = named.Underlying().(*types.Interface)
+	instance.TypeParams = nil
+	instance.TypeArgs = typeList(named.TypeArgs())
+	return instance, nil
+}
+
+// instantiate returns the types strct and iface are checked with. Generic types are never checked uninstantiated:
+//   - if iface was instantiated with as many type arguments as strct has type parameters, strct is instantiated with them,
+//   - otherwise strct is instantiated with its own type parameters, the way its methods see it. A generic iface
+//     with as many type parameters is then instantiated with them too, so that lru[K, V] implements Cache[K, V].
+//
+// Non generic types are returned as is.
+func instantiate(strct Struct, iface Interface) (types.Type, *types.Interface) {
+	named, ok := strct.Obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		return strct.Obj.Type(), iface.Type
+	}
+	tparams := named.TypeParams()
+
+	if len(iface.TypeArgs) == tparams.Len() {
+		if instance, err := types.Instantiate(nil, named, iface.TypeArgs, true); err == nil {
+			return instance, iface.Type
+		}
+	}
+
+	own := make([]types.Type, 0, tparams.Len())
+	for i := 0; i < tparams.Len(); i++ {
+		own = append(own, tparams.At(i))
+	}
+	instance, err := types.Instantiate(nil, named, own, false)
+	if err != nil {
+		return strct.Obj.Type(), iface.Type
+	}
+	// a synthetic iface, see ExportedMethodsOnly, can't be instantiated again
+	if iface.TypeParams.Len() != tparams.Len() || iface.Type != iface.Named.Underlying() {
+		return instance, iface.Type
+	}
+	ifaceInstance, err := types.Instantiate(nil, iface.Named, own, false)
+	if err != nil {
+		return instance, iface.Type
+	}
+	return instance, ifaceInstance.Underlying().(*types.Interface)
+}
+
+// genericName returns the name of the type obj, followed by its type parameters if it is generic, like Cache[K, V].
+func genericName(obj *types.TypeName) string {
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		return obj.Name()
+	}
+	names := make([]string, 0, named.TypeParams().Len())
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		names = append(names, named.TypeParams().At(i).Obj().Name())
+	}
+	return obj.Name() + "[" + strings.Join(names, ", ") + "]"
+}
+
+// Instantiations returns the distinct instantiations of the generic type obj that appear in pkgs,
+// like Cache[string, int], in the order they are found. Instantiations with type parameters, as in generic code, are left out.
+func Instantiations(pkgs []*packages.Package, obj *types.TypeName) []*types.Named {
+	found := make([]*types.Named, 0)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		// sort the instances by position, the map order isn't stable
+		idents := make([]*ast.Ident, 0)
+		for ident, instance := range pkg.TypesInfo.Instances {
+			if named, ok := instance.Type.(*types.Named); ok && named.Origin().Obj() == obj {
+				idents = append(idents, ident)
+			}
+		}
+		sort.Slice(idents, func(i, j int) bool { return idents[i].Pos() < idents[j].Pos() })
+
+		for _, ident := range idents {
+			named := pkg.TypesInfo.Instances[ident].Type.(*types.Named)
+			if !isConcrete(named) || containsIdentical(found, named) {
+				continue
+			}
+			found = append(found, named)
+		}
+	}
+	return found
+}
+
+// isConcrete reports whether none of the type arguments of named is a type parameter, as in the
+// instantiations inside generic code like func (c *lru[K, V]) Clone() *lru[K, V].
+func isConcrete(named *types.Named) bool {
+	for i := 0; i < named.TypeArgs().Len(); i++ {
+		if _, ok := named.TypeArgs().At(i).(*types.TypeParam); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func containsIdentical(list []*types.Named, t *types.Named) bool {
+	for _, other := range list {
+		if types.Identical(other, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// InstantiatedImplementers are the implementers of one instantiation of an interface.
+type InstantiatedImplementers struct {
+	// Name is the name of the interface instantiation, like Cache[string, int].
+	Name         string
+	Interface    Interface
+	Implementers []Implementer
+}
+
+// FindInstantiatedImplementers checks strcts against the instantiations of iface found in pkgs if iface is generic,
+// or against iface itself otherwise. Generic types are checked through their instantiations found in pkgs and
+// reported under their instantiated name, like lru[string, int].
+func FindInstantiatedImplementers(pkgs []*packages.Package, strcts []Struct, iface Interface) []InstantiatedImplementers {
+	ifaces := []InstantiatedImplementers{{Name: iface.Name, Interface: iface}}
+	if len(iface.TypeArgs) > 0 {
+		args := make([]string, 0, len(iface.TypeArgs))
+		for _, arg := range iface.TypeArgs {
+			args = append(args, types.TypeString(arg, types.RelativeTo(iface.Pkg)))
+		}
+		ifaces[0].Name += "[" + strings.Join(args, ", ") + "]"
+	}
+	if iface.TypeParams.Len() > 0 {
+		// a synthetic interface, see ExportedMethodsOnly, has to stay one once instantiated
+		synthetic := iface.Type != iface.Named.Underlying()
+		ifaces = ifaces[:0]
+		for _, named := range Instantiations(pkgs, iface.Named.Obj()) {
+			instance := iface
+			instance.Type = named.Underlying().(*types.Interface)
+			if synthetic {
+				instance.Type = ExportedMethodsOnly(instance.Type)
+			}
+			instance.TypeParams = nil
+			instance.TypeArgs = typeList(named.TypeArgs())
+			ifaces = append(ifaces, InstantiatedImplementers{Name: instanceName(named), Interface: instance})
+		}
+	}
+
+	// the instantiations of the generic types, looked up once for all the interface instantiations
+	instances := make(map[*types.TypeName][]*types.Named)
+	for _, strct := range strcts {
+		if named, ok := strct.Obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			instances[strct.Obj] = Instantiations(pkgs, strct.Obj)
+		}
+	}
+
+	for i := range ifaces {
+		ifaceType := ifaces[i].Interface.Type
+		for _, strct := range strcts {
+			candidates := []types.Type{strct.Obj.Type()}
+			if named, ok := strct.Obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+				candidates = candidates[:0]
+				for _, instance := range instances[strct.Obj] {
+					candidates = append(candidates, instance)
+				}
+			}
+
+			for _, t := range candidates {
+				impl := Implementer{Struct: strct}
+				if named, ok := t.(*types.Named); ok && named.TypeArgs().Len() > 0 {
+					impl.Name = instanceName(named)
+					impl.Type = named.Underlying()
+				}
+				switch {
+				case types.Implements(t, ifaceType):
+					impl.Receiver = ValueReceiver
+				case types.Implements(PointerTo(t), ifaceType):
+					impl.Receiver = PointerReceiver
+				default:
+					continue
+				}
+				ifaces[i].Implementers = append(ifaces[i].Implementers, impl)
+			}
+		}
+	}
+	return ifaces
+}
+
+// instanceName returns the name of the instantiated type named with its type arguments, like Cache[string, int].
+// Types of other packages are qualified with their package name.
+func instanceName(named *types.Named) string {
+	return types.TypeString(named, func(pkg *types.Package) string {
+		if pkg == named.Obj().Pkg() {
+			return ""
+		}
+		return pkg.Name()
+	})
+}
+
+func typeList(list *types.TypeList) []types.Type {
+	result := make([]types.Type, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		result = append(result, list.At(i))
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+package inspector
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Gitignore holds the patterns of the .gitignore file found at the root of the git repository of the scanned tree.
+// Only the top level .gitignore is considered.
+type Gitignore struct {
+	root     string
+	patterns []gitignorePattern
+}
+
+type gitignorePattern struct {
+	re     *regexp.Regexp
+	negate bool
+	// anchored patterns are matched against the whole path relative to the root
+	// instead of against a single path element.
+	anchored bool
+}
+
+// LoadGitignore reads the .gitignore file at the root of the git repository dir belongs to, the closest of dir and
+// its parents with a .git entry, or in dir itself outside of a repository. A missing file yields a Gitignore that
+// ignores nothing.
+func LoadGitignore(dir string) (*Gitignore, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root := repoRoot(absDir)
+
+	f, err := os.Open(filepath.Join(root, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Gitignore{root: root}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseGitignore(root, f)
+}
+
+// repoRoot returns the closest of dir and its parents with a .git directory, or a .git file like in worktrees
+// and submodules. dir is returned if there is none.
+func repoRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// parseGitignore reads the patterns of a .gitignore file in the directory root from r.
+func parseGitignore(root string, r io.Reader) (*Gitignore, error) {
+	gi := &Gitignore{root: root}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p gitignorePattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		// only directories are checked, so a trailing slash doesn't change anything
+		line = strings.TrimSuffix(line, "/")
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		var err error
+		p.re, err = regexp.Compile(globToRegexp(line))
+		if err != nil {
+			// skip patterns we can't make sense of instead of failing the whole scan
+			continue
+		}
+		gi.patterns = append(gi.patterns, p)
+	}
+
+	return gi, scanner.Err()
+}
+
+// Ignored reports whether the directory dir is ignored, either directly or because one of its parents is.
+func (g *Gitignore) Ignored(dir string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(g.root, absDir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	elems := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range elems {
+		if g.match(strings.Join(elems[:i+1], "/"), elems[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether the directory with the relative path rel and the base name base is ignored.
+// Later patterns override earlier ones, as in git.
+func (g *Gitignore) match(rel, base string) bool {
+	ignored := false
+	for _, p := range g.patterns {
+		subject := base
+		if p.anchored {
+			subject = rel
+		}
+		if p.re.MatchString(subject) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp translates a gitignore glob into an anchored regular expression.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**"):
+			sb.WriteString("(/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// FilterIgnoredPackages drops the packages whose directory is ignored by gi.
+func FilterIgnoredPackages(pkgs []*packages.Package, gi *Gitignore) []*packages.Package {
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) > 0 && gi.Ignored(filepath.Dir(pkg.GoFiles[0])) {
+			continue
+		}
+		kept = append(kept, pkg)
+	}
+	return kept
+}
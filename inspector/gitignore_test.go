@@ -0,0 +1,124 @@
+package inspector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitignoreIgnored(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		lines   []string
+		ignored []string
+		kept    []string
+	}{
+		{
+			name:    "name at any level",
+			lines:   []string{"build"},
+			ignored: []string{"build", "cmd/build", "build/sub"},
+			kept:    []string{"builder", "cmd/rebuild"},
+		},
+		{
+			name:    "directory pattern",
+			lines:   []string{"gen/"},
+			ignored: []string{"gen", "internal/gen", "gen/sub"},
+			kept:    []string{"generated"},
+		},
+		{
+			name:    "leading slash anchors",
+			lines:   []string{"/build"},
+			ignored: []string{"build", "build/sub"},
+			kept:    []string{"cmd/build"},
+		},
+		{
+			name:    "middle slash anchors",
+			lines:   []string{"docs/internal"},
+			ignored: []string{"docs/internal", "docs/internal/sub"},
+			kept:    []string{"internal", "pkg/docs/internal"},
+		},
+		{
+			name:    "double star",
+			lines:   []string{"**/fixtures", "tools/**/gen"},
+			ignored: []string{"fixtures", "a/b/fixtures", "tools/gen", "tools/x/y/gen"},
+			kept:    []string{"tools", "gen", "a/gen"},
+		},
+		{
+			name:    "globs",
+			lines:   []string{"tmp*", "out?", "v[0-9]", "x[!a]"},
+			ignored: []string{"tmp", "tmp2", "a/tmpdir", "out1", "v1", "xb"},
+			kept:    []string{"out", "out12", "va", "xa", "atmp"},
+		},
+		{
+			name:    "negation",
+			lines:   []string{"gen*", "!generated"},
+			ignored: []string{"gen", "gen2"},
+			kept:    []string{"generated", "a/generated"},
+		},
+		{
+			name:    "later pattern wins",
+			lines:   []string{"!gen", "gen"},
+			ignored: []string{"gen"},
+		},
+		{
+			name:    "parent excludes child",
+			lines:   []string{"vendor/", "!vendor/keep"},
+			ignored: []string{"vendor", "vendor/keep", "vendor/keep/sub"},
+		},
+		{
+			name:    "comments, blank lines and escapes",
+			lines:   []string{"# build", "", "   ", `\#hash`, `\!bang`},
+			ignored: []string{"#hash", "!bang"},
+			kept:    []string{"build", "# build"},
+		},
+		{
+			name: "outside of the root",
+			// the root itself and the directories outside of it are never ignored
+			lines: []string{"*"},
+			kept:  []string{".", "..", "../other"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.FromSlash("/repo")
+			gi, err := parseGitignore(root, strings.NewReader(strings.Join(tt.lines, "\n")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, dir := range tt.ignored {
+				if !gi.Ignored(filepath.Join(root, filepath.FromSlash(dir))) {
+					t.Errorf("%s isn't ignored", dir)
+				}
+			}
+			for _, dir := range tt.kept {
+				if gi.Ignored(filepath.Join(root, filepath.FromSlash(dir))) {
+					t.Errorf("%s is ignored", dir)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadGitignoreFromSubdirectory(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{".git", "cmd/tool", "gen"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("/gen\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// like running in a subdirectory of the repository, or with -dir
+	gi, err := LoadGitignore(filepath.Join(root, "cmd", "tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gi.Ignored(filepath.Join(root, "gen")) {
+		t.Error("the .gitignore of the repository root wasn't read")
+	}
+	if gi.Ignored(filepath.Join(root, "cmd", "tool")) {
+		t.Error("cmd/tool is ignored")
+	}
+}
@@ -0,0 +1,559 @@
+// Package inspector finds the structs that implement an interface in a set of Go packages.
+//
+// The simplest entry point is FindImplementers. The other functions work on packages loaded with Load,
+// or with golang.org/x/tools/go/packages using LoadMode, so that a single load can serve several queries.
+package inspector
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadMode is the mode packages must be loaded with for the functions of this package.
+const LoadMode = packages.LoadAllSyntax | packages.NeedModule
+
+// ExportDataLoadMode loads the types from the export data the go command keeps in its build cache instead of
+// type checking the source. The cache is kept across runs and the go command only compiles the packages whose files
+// changed, which makes loading big modules again much faster. Neither the syntax nor the type information of
+// the expressions is loaded, so the functions relying on them, IndexFuncDecls, FindRegistrations,
+// FindInterfacesUsedAsParams, FindInstantiatedImplementers, FindLocalStructs and MethodDoc, find nothing.
+const ExportDataLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+	packages.NeedTypes | packages.NeedModule
+
+// Interface is an interface found in the loaded packages.
+type Interface struct {
+	Pkg  *types.Package
+	Name string
+	// Type is the interface type. It may be a synthetic interface, see ExportedMethodsOnly.
+	Type *types.Interface
+	// GoVersion is the language version the interface's package was type checked with
+	GoVersion string
+	// Named is the declared interface type.
+	Named *types.Named
+	// TypeParams are the type parameters of a generic interface that wasn't instantiated, see Instantiate.
+	TypeParams *types.TypeParamList
+	// TypeArgs are the type arguments a generic interface was instantiated with.
+	TypeArgs []types.Type
+	Position token.Position
+}
+
+// QualifiedName returns the name of the interface prefixed with the import path of its package.
+func (i Interface) QualifiedName() string {
+	return i.Pkg.Path() + "." + i.Name
+}
+
+// Struct is a named type declared at package level. Despite its name it may be any named type
+// but an interface, like a slice or a function type with methods. Kind tells which one.
+type Struct struct {
+	Obj *types.TypeName
+	// Type is the underlying type, a *types.Struct for structs.
+	Type     types.Type
+	Kind     string
+	Name     string
+	PkgPath  string
+	Position token.Position
+}
+
+func (s Struct) String() string {
+	return fmt.Sprintf("%s %s", s.Name, s.PositionString())
+}
+
+// PositionString returns the position of the struct as file:line:column.
+func (s Struct) PositionString() string {
+	return fmt.Sprintf("%s:%d:%d", s.Position.Filename, s.Position.Line, s.Position.Column)
+}
+
+// ReceiverKind tells whether a type satisfies an interface with its value or only with a pointer to it.
+type ReceiverKind string
+
+const (
+	// ValueReceiver means the value satisfies the interface, and so does the pointer.
+	ValueReceiver ReceiverKind = "value"
+	// PointerReceiver means only the pointer satisfies the interface because some methods have pointer receivers.
+	PointerReceiver ReceiverKind = "pointer"
+)
+
+// Implementer is a named type that implements an interface.
+type Implementer struct {
+	Struct
+	Receiver ReceiverKind
+}
+
+func (i Implementer) String() string {
+	return fmt.Sprintf("%s %s", i.Label(), i.PositionString())
+}
+
+// Label returns the name of the type followed by its receiver kind. Types other than structs are annotated with their kind.
+func (i Implementer) Label() string {
+	if i.Kind != "struct" {
+		return fmt.Sprintf("%s (%s, %s receiver)", i.Name, i.Kind, i.Receiver)
+	}
+	return fmt.Sprintf("%s (%s receiver)", i.Name, i.Receiver)
+}
+
+// FindInterface finds an interface with the name interfaceName in package packageName
+func FindInterface(pkgs []*packages.Package, packageName, packageDirectory, interfaceName string) (Interface, error) {
+	thePackage, err := findPackage(pkgs, packageName, packageDirectory)
+	if err != nil {
+		return Interface{}, err
+	}
+
+	return lookupInterface(preferPlainVariant(pkgs, thePackage, interfaceName), interfaceName, packageName)
+}
+
+// FindInterfaceByRef finds the interface referenced by ref, a fully qualified reference like
+// github.com/me/proj/pkg/cmd.Stringer. Unlike FindInterface, the package is matched on its exact import path.
+func FindInterfaceByRef(pkgs []*packages.Package, ref string) (Interface, error) {
+	pkgPath, interfaceName, err := ParseRef(ref)
+	if err != nil {
+		return Interface{}, err
+	}
+
+	thePackage, err := findPackageByPath(pkgs, pkgPath)
+	if err != nil {
+		return Interface{}, err
+	}
+	if len(thePackage.Errors) > 0 && thePackage.Types.Scope().Lookup(interfaceName) == nil {
+		return Interface{}, fmt.Errorf("load %q: %v", pkgPath, thePackage.Errors[0])
+	}
+
+	return lookupInterface(preferPlainVariant(pkgs, thePackage, interfaceName), interfaceName, pkgPath)
+}
+
+// FindInterfacesMatching finds the exported interfaces of the package named packageName, see FindInterface,
+// whose name matches glob, in the syntax of path.Match. They are ordered by position.
+func FindInterfacesMatching(pkgs []*packages.Package, packageName, packageDirectory, glob string) ([]Interface, error) {
+	thePackage, err := findPackage(pkgs, packageName, packageDirectory)
+	if err != nil {
+		return nil, err
+	}
+	return matchInterfaces(thePackage, glob)
+}
+
+// FindInterfacesMatchingRef is like FindInterfacesMatching, with the package and the glob given as a reference
+// like github.com/me/proj/pkg/cmd.* whose import path is matched exactly, see FindInterfaceByRef.
+func FindInterfacesMatchingRef(pkgs []*packages.Package, ref string) ([]Interface, error) {
+	pkgPath, glob, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	thePackage, err := findPackageByPath(pkgs, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	return matchInterfaces(thePackage, glob)
+}
+
+// matchInterfaces returns the exported interfaces of pkg whose name matches glob, ordered by position.
+// Aliases of interfaces are left out.
+func matchInterfaces(pkg *packages.Package, glob string) ([]Interface, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %v", glob, err)
+	}
+
+	ifaces := make([]Interface, 0)
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		if matched, _ := path.Match(glob, name); !matched || !token.IsExported(name) {
+			continue
+		}
+		// aliases are skipped, the interface they stand for is found on its own
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		if iface, ok := interfaceOf(pkg, obj); ok {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	if len(ifaces) == 0 {
+		return nil, fmt.Errorf("no exported interface matching %q in package %q", glob, pkg.PkgPath)
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return positionLess(ifaces[i].Position, ifaces[j].Position) })
+	return ifaces, nil
+}
+
+// findPackageByPath finds the package with the import path pkgPath among pkgs and their dependencies.
+func findPackageByPath(pkgs []*packages.Package, pkgPath string) (*packages.Package, error) {
+	var thePackage *packages.Package
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == pkgPath {
+			return pkg, nil
+		}
+	}
+	// the search path may not include the package, so the dependencies of the loaded packages are visited too
+	packages.Visit(pkgs, func(pkg *packages.Package) bool {
+		if thePackage == nil && pkg.PkgPath == pkgPath {
+			thePackage = pkg
+		}
+		return thePackage == nil
+	}, nil)
+	if thePackage == nil {
+		return nil, fmt.Errorf("couldn't find a package with the import path %q", pkgPath)
+	}
+	return thePackage, nil
+}
+
+// lookupInterface looks up the interface interfaceName in the scope of pkg. pkgDesc names pkg in errors.
+// A name that exists but isn't an interface is reported as such, with what it is instead.
+func lookupInterface(pkg *packages.Package, interfaceName, pkgDesc string) (Interface, error) {
+	obj := pkg.Types.Scope().Lookup(interfaceName)
+	if obj == nil {
+		return Interface{}, fmt.Errorf("no such interface %q in package %q", interfaceName, pkgDesc)
+	}
+	iface, ok := interfaceOf(pkg, obj)
+	if !ok {
+		return Interface{}, fmt.Errorf("%q in package %q is a %s, not an interface", interfaceName, pkgDesc, objectKind(obj))
+	}
+	return iface, nil
+}
+
+// objectKind describes what obj declares, like "variable" or, for types, the kind of the underlying type as in typeKind.
+func objectKind(obj types.Object) string {
+	switch obj := obj.(type) {
+	case *types.TypeName:
+		if kind := typeKind(obj.Type().Underlying()); kind != "" {
+			return kind
+		}
+		return "type parameter"
+	case *types.Var:
+		return "variable"
+	case *types.Const:
+		return "constant"
+	case *types.Func:
+		return "function"
+	}
+	return "package"
+}
+
+// ParseRef splits a fully qualified reference like github.com/me/proj/pkg/cmd.Stringer into
+// the import path and the name.
+func ParseRef(ref string) (pkgPath, name string, err error) {
+	i := strings.LastIndex(ref, ".")
+	if i <= strings.LastIndex(ref, "/") || i == 0 || i == len(ref)-1 {
+		return "", "", fmt.Errorf("invalid reference %q, expected something like github.com/me/proj/pkg.Name", ref)
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+// interfaceOf returns the interface declared by obj in pkg. ok is false if obj doesn't declare an interface.
+// obj may be an alias of an interface.
+func interfaceOf(pkg *packages.Package, obj types.Object) (iface Interface, ok bool) {
+	if _, isTypeName := obj.(*types.TypeName); !isTypeName {
+		return Interface{}, false
+	}
+	theInterface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return Interface{}, false
+	}
+
+	goVersion := "unknown"
+	if pkg.Module != nil && pkg.Module.GoVersion != "" {
+		goVersion = "go" + pkg.Module.GoVersion
+	}
+
+	named, _ := types.Unalias(obj.Type()).(*types.Named)
+	var typeParams *types.TypeParamList
+	if named != nil {
+		typeParams = named.TypeParams()
+	}
+
+	return Interface{
+		Pkg:        pkg.Types,
+		Name:       obj.Name(),
+		Type:       theInterface,
+		GoVersion:  goVersion,
+		Named:      named,
+		TypeParams: typeParams,
+		Position:   pkg.Fset.Position(obj.Pos()),
+	}, true
+}
+
+// findPackage finds the package named packageName in the directory packageDirectory or, failing that,
+// whose import path contains packageDirectory.
+func findPackage(pkgs []*packages.Package, packageName, packageDirectory string) (*packages.Package, error) {
+	// a package in the directory itself wins over the substring match of the import path, which is ambiguous
+	// when several modules have packages with the same name and path suffix, like in a go.work workspace
+	if absDir, err := filepath.Abs(packageDirectory); err == nil {
+		for _, pkg := range pkgs {
+			if pkg.Name == packageName && len(pkg.GoFiles) > 0 && filepath.Dir(pkg.GoFiles[0]) == absDir {
+				return pkg, nil
+			}
+		}
+	}
+
+	pkgFound := false
+	var thePackage *packages.Package
+	var isRootDir = packageDirectory == "." || packageDirectory == "./"
+	for _, pkg := range pkgs {
+		if pkg.Name == packageName && (strings.Contains(pkg.PkgPath, packageDirectory) || isRootDir) {
+			pkgFound = true
+			thePackage = pkg
+			break
+		}
+	}
+
+	// the search path may not include the package, so fall back to the dependencies of the loaded packages
+	if !pkgFound && !isRootDir {
+		packages.Visit(pkgs, func(pkg *packages.Package) bool {
+			if !pkgFound && pkg.Name == packageName && strings.Contains(pkg.PkgPath, packageDirectory) {
+				pkgFound = true
+				thePackage = pkg
+			}
+			return !pkgFound
+		}, nil)
+	}
+
+	if !pkgFound {
+		return nil, fmt.Errorf("couldn't find a package named %q in %q", packageName, packageDirectory)
+	}
+	return thePackage, nil
+}
+
+// Implementers returns all structs from strcts that implement the interface iface. With test variants, see
+// PreferTestVariants, every struct is checked against iface as declared in the variant of its package it sees.
+func Implementers(strcts []Struct, iface Interface) []Implementer {
+	result := make([]Implementer, 0)
+	variants := newInterfaceVariants(iface)
+	for _, strct := range strcts {
+		impl := Implementer{Struct: strct}
+		t, ifaceType := instantiate(strct, variants.of(strct))
+		switch {
+		case types.Implements(t, ifaceType):
+			impl.Receiver = ValueReceiver
+		case types.Implements(PointerTo(t), ifaceType):
+			impl.Receiver = PointerReceiver
+		default:
+			continue
+		}
+		result = append(result, impl)
+	}
+
+	return result
+}
+
+// ParallelImplementers is like Implementers, but checks strcts in up to jobs goroutines, GOMAXPROCS of them if jobs
+// isn't positive. The implementers are returned in the order of strcts.
+func ParallelImplementers(strcts []Struct, iface Interface, jobs int) []Implementer {
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	if jobs > len(strcts) {
+		jobs = len(strcts)
+	}
+	if jobs <= 1 {
+		return Implementers(strcts, iface)
+	}
+
+	// every goroutine checks a contiguous chunk and writes to its own slot, which keeps the order
+	perChunk := make([][]Implementer, jobs)
+	chunkSize := (len(strcts) + jobs - 1) / jobs
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		start, end := i*chunkSize, min((i+1)*chunkSize, len(strcts))
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			perChunk[i] = Implementers(strcts[start:end], iface)
+		}()
+	}
+	wg.Wait()
+
+	result := make([]Implementer, 0)
+	for _, impls := range perChunk {
+		result = append(result, impls...)
+	}
+	return result
+}
+
+// PointerTo returns the pointer type to t, whose method set is the largest one available for t.
+// Types that already are pointers, including named pointer types, are returned as is so they don't
+// end up double wrapped like **Base.
+func PointerTo(t types.Type) types.Type {
+	if _, ok := t.Underlying().(*types.Pointer); ok {
+		return t
+	}
+	return types.NewPointer(t)
+}
+
+// FindStructs finds all named types in pkgs that may implement an interface, that is all of them but interfaces. The packages are scanned by up to jobs goroutines,
+// GOMAXPROCS of them if jobs isn't positive. The result is ordered by package path and position, and a type
+// loaded more than once, like a package and its test variant, is only reported once.
+func FindStructs(pkgs []*packages.Package, jobs int) []Struct {
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+
+	// every package writes to its own slot, so the workers don't need to synchronize
+	perPackage := make([][]Struct, len(pkgs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				perPackage[i] = packageStructs(pkgs[i])
+			}
+		}()
+	}
+	for i := range pkgs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	strcts := make([]Struct, 0)
+	for _, s := range perPackage {
+		strcts = append(strcts, s...)
+	}
+	return SortStructs(strcts)
+}
+
+// packageStructs returns the named types declared at the top level of pkg, except for interfaces.
+func packageStructs(pkg *packages.Package) []Struct {
+	strcts := make([]Struct, 0)
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		// only type declarations. Aliases are skipped since the type they stand for is found on its own
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		kind := typeKind(obj.Type().Underlying())
+		if kind == "" {
+			continue
+		}
+		strcts = append(strcts, Struct{
+			Obj:      obj,
+			Type:     obj.Type().Underlying(),
+			Kind:     kind,
+			Name:     genericName(obj),
+			PkgPath:  pkg.PkgPath,
+			Position: pkg.Fset.Position(obj.Pos())})
+	}
+	return strcts
+}
+
+// FindLocalStructs finds the named types declared inside functions of pkgs, like the small fakes of tests,
+// which FindStructs skips. They are named after their function, like TestFetch.fakeFetcher. Since local types
+// can't have methods of their own, they only implement interfaces through the fields they embed.
+// The syntax and type information of pkgs is needed.
+func FindLocalStructs(pkgs []*packages.Package) []Struct {
+	strcts := make([]Struct, 0)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for ident, obj := range pkg.TypesInfo.Defs {
+			obj, ok := obj.(*types.TypeName)
+			if !ok || obj.IsAlias() || obj.Parent() == nil || obj.Parent() == pkg.Types.Scope() {
+				continue
+			}
+			kind := typeKind(obj.Type().Underlying())
+			if kind == "" {
+				continue
+			}
+			strcts = append(strcts, Struct{
+				Obj:      obj,
+				Type:     obj.Type().Underlying(),
+				Kind:     kind,
+				Name:     enclosingFunc(pkg, ident.Pos()) + genericName(obj),
+				PkgPath:  pkg.PkgPath,
+				Position: pkg.Fset.Position(obj.Pos())})
+		}
+	}
+	return SortStructs(strcts)
+}
+
+// enclosingFunc returns the name of the function declaration of pkg containing pos followed by a dot,
+// like Fetch. or Client.Fetch. for methods.
+func enclosingFunc(pkg *packages.Package, pos token.Pos) string {
+	for _, f := range pkg.Syntax {
+		if pos < f.Pos() || pos >= f.End() {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || pos < fn.Pos() || pos >= fn.End() {
+				continue
+			}
+			if fn.Recv != nil && len(fn.Recv.List) == 1 {
+				return receiverTypeName(fn.Recv.List[0].Type) + "." + fn.Name.Name + "."
+			}
+			return fn.Name.Name + "."
+		}
+	}
+	return ""
+}
+
+// receiverTypeName returns the name of the type of a method receiver, without pointer and type parameters.
+func receiverTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// typeKind describes the underlying type t of a named type, like "struct" or "func".
+// The empty string is returned for interfaces and type parameters, which are never reported as implementers.
+func typeKind(t types.Type) string {
+	switch t := t.(type) {
+	case *types.Struct:
+		return "struct"
+	case *types.Basic:
+		return t.Name()
+	case *types.Slice:
+		return "slice"
+	case *types.Array:
+		return "array"
+	case *types.Map:
+		return "map"
+	case *types.Signature:
+		return "func"
+	case *types.Chan:
+		return "chan"
+	case *types.Pointer:
+		return "pointer"
+	}
+	return ""
+}
+
+// QualifiedName returns the name of obj prefixed with the import path of its package.
+func QualifiedName(obj types.Object) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// PackageNameQualifier qualifies types by their package name, the way they are written in source code.
+// It is meant for types.TypeString.
+func PackageNameQualifier(pkg *types.Package) string {
+	return pkg.Name()
+}
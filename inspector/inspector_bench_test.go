@@ -0,0 +1,23 @@
+package inspector
+
+import (
+	"fmt"
+	"testing"
+)
+
+func BenchmarkFindStructs(b *testing.B) {
+	pkgs := load(b, Query{Dir: selfTestModule(b)})
+	iface, err := FindInterface(pkgs, "shapes", "shapes", "Polygon")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// FindStructs scans one package per goroutine and ParallelImplementers one chunk of the types per goroutine
+	for _, jobs := range []int{1, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelImplementers(FindStructs(pkgs, jobs), iface, jobs)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package inspector
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// writeModule writes files, keyed by their slash separated path, into a new temporary directory
+// and returns it. A go.mod file declaring the module example.com/m is added unless files has one.
+func writeModule(t testing.TB, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if _, ok := files["go.mod"]; !ok {
+		files["go.mod"] = "module example.com/m\n\ngo 1.22\n"
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// selfTestModule copies the fixtures of the self-test into a new module named selftest and returns its directory.
+func selfTestModule(t testing.TB) string {
+	t.Helper()
+	files := map[string]string{"go.mod": "module selftest\n\ngo 1.19\n"}
+	root := filepath.Join("..", "testdata", "selftest")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writeModule(t, files)
+}
+
+func load(t testing.TB, q Query) []*packages.Package {
+	t.Helper()
+	pkgs, err := Load(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("the fixtures don't compile")
+	}
+	return pkgs
+}
+
+// names returns the sorted names of impls.
+func names(impls []Implementer) []string {
+	result := make([]string, 0, len(impls))
+	for _, impl := range impls {
+		result = append(result, impl.Name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestFindInterface(t *testing.T) {
+	pkgs := load(t, Query{Dir: selfTestModule(t)})
+
+	iface, err := FindInterface(pkgs, "shapes", "shapes", "Polygon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iface.QualifiedName() != "selftest/shapes.Polygon" {
+		t.Errorf("got %s, want selftest/shapes.Polygon", iface.QualifiedName())
+	}
+	if got := iface.Type.NumMethods(); got != 2 {
+		t.Errorf("got %d methods, want Area and Corners", got)
+	}
+
+	for _, tt := range []struct {
+		packageName, interfaceName string
+	}{
+		{"shapes", "Missing"},
+		{"missing", "Shape"},
+	} {
+		if _, err := FindInterface(pkgs, tt.packageName, "shapes", tt.interfaceName); err == nil {
+			t.Errorf("FindInterface(%s.%s) succeeded, want an error", tt.packageName, tt.interfaceName)
+		}
+	}
+}
+
+func TestFindInterfaceNotAnInterface(t *testing.T) {
+	pkgs := load(t, Query{Dir: selfTestModule(t)})
+	_, err := FindInterfaceByRef(pkgs, "selftest/impl.circle")
+	if err == nil || err.Error() != `"circle" in package "selftest/impl" is a struct, not an interface` {
+		t.Errorf("got %v, want an error telling circle is a struct", err)
+	}
+}
+
+func TestImplementers(t *testing.T) {
+	pkgs := load(t, Query{Dir: selfTestModule(t)})
+	strcts := FindStructs(pkgs, 0)
+
+	for _, tt := range []struct {
+		iface string
+		want  []string
+	}{
+		{"Shape", []string{"areaFunc", "base", "circle", "cube", "rect", "square"}},
+		{"Polygon", []string{"cube", "rect", "square"}},
+		{"ReadCloser", []string{"file"}},
+		{"Getter", []string{"box[T]"}},
+		{"Solid", []string{"cube"}},
+	} {
+		iface, err := FindInterface(pkgs, "shapes", "shapes", tt.iface)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(Implementers(strcts, iface)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Implementers(%s) = %v, want %v", tt.iface, got, tt.want)
+		}
+	}
+}
+
+func TestFindStructs(t *testing.T) {
+	pkgs := load(t, Query{Dir: selfTestModule(t)})
+
+	got := make([]string, 0)
+	for _, strct := range FindStructs(pkgs, 0) {
+		got = append(got, strct.Name+" "+strct.Kind)
+	}
+	// ordered by position, without the aliases and the interfaces
+	want := []string{
+		"circle struct", "square struct", "base struct", "rect struct", "triangle struct", "file struct",
+		"namedSquarePtr pointer", "areaFunc func", "box[T] struct", "intBox struct", "cube struct",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindStructsJobs(t *testing.T) {
+	pkgs := load(t, Query{Dir: selfTestModule(t)})
+	iface, err := FindInterface(pkgs, "shapes", "shapes", "Shape")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the packages and the types are split differently among the goroutines, the result must not change
+	strcts := FindStructs(pkgs, 1)
+	impls := ParallelImplementers(strcts, iface, 1)
+	for _, jobs := range []int{2, 3, 8, 64} {
+		for run := 0; run < 10; run++ {
+			if got := FindStructs(pkgs, jobs); !reflect.DeepEqual(got, strcts) {
+				t.Fatalf("FindStructs with %d jobs = %v, want %v", jobs, got, strcts)
+			}
+			if got := ParallelImplementers(strcts, iface, jobs); !reflect.DeepEqual(got, impls) {
+				t.Fatalf("ParallelImplementers with %d jobs = %v, want %v", jobs, got, impls)
+			}
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package inspector
+
+import (
+	"errors"
+	"fmt"
+	"go/version"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+)
+
+// LangModfile writes a copy of the go.mod file in dir whose go directive is set to lang and returns
+// its path, to be passed to the go command with -modfile. The type checker uses the go directive as
+// the language version, so this makes the whole load behave as if the module targeted lang.
+// The caller removes the returned temporary directory.
+func LangModfile(dir, lang string) (path, tmpDir string, err error) {
+	if !version.IsValid(lang) {
+		return "", "", fmt.Errorf("invalid language version %q, expected something like go1.21", lang)
+	}
+
+	gomod := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return "", "", err
+	}
+	f, err := modfile.Parse(gomod, data, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := f.AddGoStmt(strings.TrimPrefix(lang, "go")); err != nil {
+		return "", "", err
+	}
+	// the toolchain line must not be older than the go line
+	f.DropToolchainStmt()
+	formatted, err := f.Format()
+	if err != nil {
+		return "", "", err
+	}
+
+	tmpDir, err = os.MkdirTemp("", "interface-inspector-lang")
+	if err != nil {
+		return "", "", err
+	}
+	path = filepath.Join(tmpDir, "go.mod")
+	if err := os.WriteFile(path, formatted, 0o644); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+
+	// the go command expects the checksums next to the alternate go.mod
+	sum, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "go.sum"), sum, 0o644); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+
+	return path, tmpDir, nil
+}
+
+// StructScopePattern turns a directory or an import path into a package pattern.
+func StructScopePattern(scope string) (pattern string, isDir bool) {
+	if info, err := os.Stat(scope); err == nil && info.IsDir() {
+		if filepath.IsAbs(scope) || strings.HasPrefix(scope, ".") {
+			return scope, true
+		}
+		return "./" + scope, true
+	}
+	return scope, false
+}
+
+// InterfacePackagePattern returns the pattern that loads the package in packageDirectory.
+func InterfacePackagePattern(packageDirectory string) string {
+	if filepath.IsAbs(packageDirectory) || strings.HasPrefix(packageDirectory, ".") {
+		return packageDirectory
+	}
+	return "./" + packageDirectory
+}
+
+// FilterStructScope returns the package of pkgs selected by scope, as returned by StructScopePattern.
+func FilterStructScope(pkgs []*packages.Package, scope string, isDir bool) ([]*packages.Package, error) {
+	absScope, err := filepath.Abs(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		var matches bool
+		if isDir {
+			matches = len(pkg.GoFiles) > 0 && filepath.Dir(pkg.GoFiles[0]) == absScope
+		} else {
+			matches = pkg.PkgPath == scope
+		}
+		if !matches {
+			continue
+		}
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("load %q: %v", scope, pkg.Errors[0])
+		}
+		return []*packages.Package{pkg}, nil
+	}
+
+	return nil, fmt.Errorf("no package found for %q", scope)
+}
+
+// FilterDirectChildren keeps the packages whose import path is exactly one path segment below parent.
+func FilterDirectChildren(pkgs []*packages.Package, parent string) []*packages.Package {
+	parentSegments := strings.Split(strings.Trim(parent, "/"), "/")
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		segments := strings.Split(pkg.PkgPath, "/")
+		if len(segments) != len(parentSegments)+1 {
+			continue
+		}
+		isChild := true
+		for i, segment := range parentSegments {
+			if segments[i] != segment {
+				isChild = false
+				break
+			}
+		}
+		if isChild {
+			kept = append(kept, pkg)
+		}
+	}
+	return kept
+}
+
+// FilterVendorPackages drops the vendored packages, whose import path has a vendor element.
+func FilterVendorPackages(pkgs []*packages.Package) []*packages.Package {
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if strings.HasPrefix(pkg.PkgPath, "vendor/") || strings.Contains(pkg.PkgPath, "/vendor/") {
+			continue
+		}
+		kept = append(kept, pkg)
+	}
+	return kept
+}
+
+// FilterExcludedPackages drops the packages whose import path, or directory relative to dir, matches one of globs.
+// The globs are in the syntax of path.Match. A glob ending in /... also matches the paths below, like internal/gen/...
+func FilterExcludedPackages(pkgs []*packages.Package, dir string, globs []string) ([]*packages.Package, error) {
+	for _, glob := range globs {
+		if _, err := path.Match(strings.TrimSuffix(glob, "/..."), ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %v", glob, err)
+		}
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		paths := []string{pkg.PkgPath}
+		if len(pkg.GoFiles) > 0 {
+			if rel, err := filepath.Rel(absDir, filepath.Dir(pkg.GoFiles[0])); err == nil {
+				paths = append(paths, filepath.ToSlash(rel))
+			}
+		}
+		if !matchesAnyGlob(globs, paths) {
+			kept = append(kept, pkg)
+		}
+	}
+	return kept, nil
+}
+
+// FilterPatternPackages keeps the packages matched by one of patterns, in the package pattern syntax of the
+// go command: directories relative to dir like ./internal/..., or import paths like github.com/me/proj/....
+// It lets packages loaded once for several patterns be split by pattern again.
+func FilterPatternPackages(pkgs []*packages.Package, dir string, patterns []string) ([]*packages.Package, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		rel := ""
+		if len(pkg.GoFiles) > 0 {
+			if r, err := filepath.Rel(absDir, filepath.Dir(pkg.GoFiles[0])); err == nil && r != ".." && !strings.HasPrefix(r, "../") {
+				rel = filepath.ToSlash(r)
+			}
+		}
+		for _, pattern := range patterns {
+			if matchesPattern(pattern, pkg.PkgPath, rel) {
+				kept = append(kept, pkg)
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
+// matchesPattern reports whether the package with the import path pkgPath, in the directory rel relative to the
+// directory patterns are resolved in, matches pattern. rel is empty for packages outside of that directory.
+func matchesPattern(pattern, pkgPath, rel string) bool {
+	if pattern == "." || strings.HasPrefix(pattern, "./") {
+		if rel == "" {
+			return false
+		}
+		pattern, pkgPath = path.Clean(pattern), rel
+	}
+	base, recursive := strings.CutSuffix(pattern, "/...")
+	switch {
+	case pattern == "...":
+		return true
+	case recursive && base == ".":
+		return true
+	case recursive:
+		return pkgPath == base || strings.HasPrefix(pkgPath, base+"/")
+	}
+	return pkgPath == pattern
+}
+
+// matchesAnyGlob reports whether one of paths matches one of globs, see FilterExcludedPackages.
+func matchesAnyGlob(globs, paths []string) bool {
+	for _, glob := range globs {
+		base, recursive := strings.CutSuffix(glob, "/...")
+		for _, p := range paths {
+			// with /..., the parents of p are tried too
+			for ; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+				if matched, _ := path.Match(base, p); matched {
+					return true
+				}
+				if !recursive {
+					break
+				}
+			}
+		}
+	}
+	return false
+}
+
+// DependencyPackages returns the packages of the other modules pkgs depend on, directly or not, like the
+// third-party packages of go.mod or vendor/. The standard library is left out. With modules, only the packages
+// of the modules whose path starts with one of them are returned.
+func DependencyPackages(pkgs []*packages.Package, modules []string) []*packages.Package {
+	roots := make(map[*packages.Package]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		roots[pkg] = true
+	}
+
+	deps := make([]*packages.Package, 0)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if roots[pkg] || pkg.Module == nil || pkg.Module.Main || pkg.Types == nil {
+			return
+		}
+		matches := len(modules) == 0
+		for _, module := range modules {
+			if strings.HasPrefix(pkg.Module.Path, module) {
+				matches = true
+				break
+			}
+		}
+		if matches {
+			deps = append(deps, pkg)
+		}
+	})
+	return deps
+}
+
+// TestStructs keeps the types declared in _test.go files, like the fakes of tests. The packages must have been
+// loaded with packages.Config.Tests for them to be found.
+func TestStructs(strcts []Struct) []Struct {
+	kept := make([]Struct, 0)
+	for _, strct := range strcts {
+		if strings.HasSuffix(strct.Position.Filename, "_test.go") {
+			kept = append(kept, strct)
+		}
+	}
+	return kept
+}
+
+// PreferTestVariants cleans up packages loaded with packages.Config.Tests. Every package with tests is
+// loaded twice, once on its own and once with its _test.go files as part of the test binary. Only the
+// latter is kept so that types aren't reported twice. The generated main packages of the test binaries are dropped.
+// The other packages still refer to the types of the former, which FindInterface and FindInterfaceByRef look the
+// interface up in, and Implementers checks every type against the interface of the variant it refers to.
+func PreferTestVariants(pkgs []*packages.Package) []*packages.Package {
+	hasTestVariant := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.ID != pkg.PkgPath {
+			hasTestVariant[pkg.PkgPath] = true
+		}
+	}
+
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test") || (pkg.ID == pkg.PkgPath && hasTestVariant[pkg.PkgPath]) {
+			continue
+		}
+		kept = append(kept, pkg)
+	}
+	return kept
+}
+
+// DropExternalPackage drops the package with the import path pkgPath from pkgs unless it belongs to the main module.
+// It is meant for a package that is only loaded to look up an interface, like io for io.Reader.
+func DropExternalPackage(pkgs []*packages.Package, pkgPath string) []*packages.Package {
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == pkgPath && (pkg.Module == nil || !pkg.Module.Main) {
+			continue
+		}
+		kept = append(kept, pkg)
+	}
+	return kept
+}
@@ -0,0 +1,319 @@
+package inspector
+
+import (
+	"context"
+	"go/types"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// rangeOverInt implements Counter with a range over an int, which needs go1.22.
+const rangeOverInt = `package p
+
+type Counter interface{ Count() int }
+
+type counter struct{}
+
+func (counter) Count() int {
+	n := 0
+	for range 3 {
+		n++
+	}
+	return n
+}
+`
+
+func TestLangModfile(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		// goDirective is the go directive of the fixture's go.mod and lang the version passed to LangModfile, if any
+		goDirective string
+		lang        string
+		wantVersion string
+		wantError   string
+	}{
+		{name: "module version", goDirective: "1.22", wantVersion: "go1.22"},
+		{name: "older module", goDirective: "1.21", wantVersion: "go1.21", wantError: "requires go1.22"},
+		{name: "older lang", goDirective: "1.22", lang: "go1.21", wantVersion: "go1.21", wantError: "requires go1.22"},
+		{name: "newer lang", goDirective: "1.21", lang: "go1.22", wantVersion: "go1.22"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				"go.mod": "module example.com/m\n\ngo " + tt.goDirective + "\n",
+				"p/p.go": rangeOverInt,
+			})
+			q := Query{Dir: dir, Interface: "example.com/m/p.Counter"}
+			if tt.lang != "" {
+				modfile, tmpDir, err := LangModfile(dir, tt.lang)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer os.RemoveAll(tmpDir)
+				q.BuildFlags = []string{"-modfile=" + modfile}
+			}
+
+			pkgs, err := Load(context.Background(), q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var errs []string
+			packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+				for _, err := range pkg.Errors {
+					errs = append(errs, err.Error())
+				}
+			})
+			gotError := strings.Join(errs, "\n")
+			if tt.wantError == "" && gotError != "" {
+				t.Errorf("got errors %s, want none", gotError)
+			}
+			if tt.wantError != "" && !strings.Contains(gotError, tt.wantError) {
+				t.Errorf("got errors %q, want one containing %q", gotError, tt.wantError)
+			}
+
+			iface, err := FindInterfaceByRef(pkgs, q.Interface)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if iface.GoVersion != tt.wantVersion {
+				t.Errorf("got language version %s, want %s", iface.GoVersion, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestLangModfileInvalidVersion(t *testing.T) {
+	dir := writeModule(t, map[string]string{"p/p.go": rangeOverInt})
+	if _, _, err := LangModfile(dir, "1.21"); err == nil {
+		t.Error("LangModfile accepted 1.21, want an error asking for go1.21")
+	}
+}
+
+// testVariants has implementers of foo.Doer on both sides of the test variant of foo: in its _test.go files,
+// in its external test package, in bar, which only sees foo, and in baz, which the external test package imports
+// and so is loaded against the test variant of foo too.
+var testVariants = map[string]string{
+	"foo/foo.go": `package foo
+
+type Arg struct{}
+
+type Doer interface{ Do(Arg) }
+`,
+	"foo/foo_test.go": `package foo
+
+type fakeDoer struct{}
+
+func (fakeDoer) Do(Arg) {}
+
+// testDoer is only declared in the test variant.
+type testDoer interface{ Do(Arg) }
+`,
+	"foo/ext_test.go": `package foo_test
+
+import (
+	"example.com/m/baz"
+	"example.com/m/foo"
+)
+
+type extDoer struct{}
+
+func (extDoer) Do(foo.Arg) {}
+
+var _ = baz.BazDoer{}
+`,
+	"bar/bar.go": `package bar
+
+import "example.com/m/foo"
+
+type RealDoer struct{}
+
+func (RealDoer) Do(foo.Arg) {}
+
+// halfDoer is a near miss.
+type halfDoer struct{}
+
+func (halfDoer) Do(int) {}
+`,
+	"baz/baz.go": `package baz
+
+import "example.com/m/foo"
+
+type BazDoer struct{}
+
+func (*BazDoer) Do(foo.Arg) {}
+`,
+}
+
+func TestTestVariants(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		tests     bool
+		testsOnly bool
+		want      []string
+	}{
+		{name: "without tests", want: []string{"BazDoer", "RealDoer"}},
+		{name: "with tests", tests: true, want: []string{"BazDoer", "RealDoer", "extDoer", "fakeDoer"}},
+		// the fakes of the tests are found although the interface is looked up outside of the test variant
+		{name: "only tests", tests: true, testsOnly: true, want: []string{"extDoer", "fakeDoer"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgs := load(t, Query{Dir: writeModule(t, testVariants), Tests: tt.tests})
+			strcts := FindStructs(pkgs, 0)
+			if tt.testsOnly {
+				strcts = TestStructs(strcts)
+			}
+
+			byRef, err := FindInterfaceByRef(pkgs, "example.com/m/foo.Doer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			byName, err := FindInterface(pkgs, "foo", "foo", "Doer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, iface := range []Interface{byRef, byName} {
+				// the interface is looked up in the package the other packages see, not in its test variant
+				if iface.Named.Obj().Pkg() != plainFoo(t, pkgs) {
+					t.Errorf("%s was looked up in the test variant of its package", iface.QualifiedName())
+				}
+				if got := names(Implementers(strcts, iface)); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Implementers = %v, want %v", got, tt.want)
+				}
+				for _, strct := range strcts {
+					if e := Explain(strct, iface); e.Receiver == "" && contains(tt.want, strct.Name) {
+						t.Errorf("Explain(%s) = %v, want an implementer", strct.Name, e.Missing)
+					}
+				}
+			}
+			if tt.tests {
+				iface, err := FindInterfaceByRef(pkgs, "example.com/m/foo.testDoer")
+				if err != nil {
+					t.Fatal(err)
+				}
+				// the packages outside of the tests can't refer to testDoer, nor to the test variant of Arg
+				want := []string{"extDoer", "fakeDoer"}
+				if got := names(Implementers(strcts, iface)); !reflect.DeepEqual(got, want) {
+					t.Errorf("Implementers(testDoer) = %v, want %v", got, want)
+				}
+			}
+			if !tt.testsOnly {
+				nearMisses := FindNearMisses(strcts, byRef)
+				if len(nearMisses) != 1 || nearMisses[0].Name != "halfDoer" {
+					t.Errorf("got near misses %v, want halfDoer", nearMisses)
+				}
+			}
+		})
+	}
+}
+
+func TestTestVariantsByRef(t *testing.T) {
+	// both foo and its test variant are loaded, which share the import path
+	pkgs := load(t, Query{Dir: writeModule(t, testVariants), Tests: true})
+
+	for _, tt := range []struct {
+		ref      string
+		testFile bool
+	}{
+		{"foo.Arg", false},
+		{"m/foo.fakeDoer", true},
+		{"bar.RealDoer", false},
+	} {
+		strct, err := FindStructByRef(pkgs, tt.ref)
+		if err != nil {
+			t.Fatalf("FindStructByRef(%s): %v", tt.ref, err)
+		}
+		if got := strings.HasSuffix(strct.Position.Filename, "_test.go"); got != tt.testFile {
+			t.Errorf("FindStructByRef(%s) found %s", tt.ref, strct.Position)
+		}
+	}
+	// the types outside of the tests are found in the package the other packages see
+	if arg, _ := FindStructByRef(pkgs, "foo.Arg"); arg.Obj.Pkg() != plainFoo(t, pkgs) {
+		t.Error("foo.Arg was looked up in the test variant of its package")
+	}
+
+	for _, a := range []string{
+		"foo.fakeDoer implements foo.Doer",
+		"bar.RealDoer implements foo.Doer",
+		"baz.BazDoer implements foo.Doer",
+		"foo.fakeDoer implements foo.testDoer",
+	} {
+		assertion, err := ParseAssertion(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := CheckAssertion(pkgs, assertion)
+		if err != nil {
+			t.Fatalf("%s: %v", a, err)
+		}
+		if e.Receiver == "" {
+			t.Errorf("%s doesn't hold: %v", a, e.Missing)
+		}
+	}
+}
+
+// plainFoo returns the package foo of the testVariants fixtures as bar sees it, without its _test.go files.
+func plainFoo(t *testing.T, pkgs []*packages.Package) *types.Package {
+	t.Helper()
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == "example.com/m/bar" {
+			return pkg.Imports["example.com/m/foo"].Types
+		}
+	}
+	t.Fatal("bar wasn't loaded")
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadGOOS(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"p/p.go": `package p
+
+type Opener interface{ Open() error }
+
+type file struct{}
+
+func (file) Open() error { return nil }
+`,
+		"p/handle_windows.go": `package p
+
+type handle struct{}
+
+func (handle) Open() error { return nil }
+`,
+		"p/fd_linux.go": `package p
+
+type fd struct{}
+
+func (fd) Open() error { return nil }
+`,
+	})
+
+	for _, tt := range []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{"fd", "file"}},
+		{"windows", []string{"file", "handle"}},
+		{"darwin", []string{"file"}},
+	} {
+		pkgs := load(t, Query{Dir: dir, Env: append(os.Environ(), "GOOS="+tt.goos)})
+		iface, err := FindInterfaceByRef(pkgs, "example.com/m/p.Opener")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(Implementers(FindStructs(pkgs, 0), iface)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GOOS=%s: got %v, want %v", tt.goos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package inspector
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// MethodReceiver describes the method a struct uses to satisfy an interface method.
+type MethodReceiver struct {
+	Method  *types.Func
+	Pointer bool
+	// PromotedFrom is the type of the embedded field the method is promoted through, if it isn't declared by the struct itself.
+	PromotedFrom types.Type
+	// Embeddings are the embedded fields the method is promoted through, starting with the one of the struct.
+	Embeddings []*types.Var
+}
+
+// MethodReceivers returns, for every method of iface, whether strct declares it on the value or on the pointer.
+// Methods strct doesn't have are left out.
+func MethodReceivers(strct Struct, iface Interface) []MethodReceiver {
+	ptr := PointerTo(strct.Obj.Type())
+	receivers := make([]MethodReceiver, 0, iface.Type.NumMethods())
+	for i := 0; i < iface.Type.NumMethods(); i++ {
+		m := iface.Type.Method(i)
+		obj, index, _ := types.LookupFieldOrMethod(ptr, false, m.Pkg(), m.Name())
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		recv := fn.Type().(*types.Signature).Recv()
+		_, isPointer := recv.Type().(*types.Pointer)
+		r := MethodReceiver{Method: fn, Pointer: isPointer}
+		// index has more than one entry when the method is promoted. The first one is the embedded field of strct.
+		if fields, ok := strct.Type.(*types.Struct); ok && len(index) > 1 && index[0] < fields.NumFields() {
+			r.PromotedFrom = fields.Field(index[0]).Type()
+			r.Embeddings = embeddedFields(strct.Type, index[:len(index)-1])
+		}
+		receivers = append(receivers, r)
+	}
+	return receivers
+}
+
+// embeddedFields follows the field indexes, as returned by types.LookupFieldOrMethod, from the struct t
+// through its embedded fields and returns the fields.
+func embeddedFields(t types.Type, index []int) []*types.Var {
+	fields := make([]*types.Var, 0, len(index))
+	for _, i := range index {
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		strct, ok := t.Underlying().(*types.Struct)
+		if !ok || i >= strct.NumFields() {
+			break
+		}
+		fields = append(fields, strct.Field(i))
+		t = strct.Field(i).Type()
+	}
+	return fields
+}
+
+// Discrepancy is a type for which types.AssignableTo and types.Implements disagree about an interface.
+type Discrepancy struct {
+	Struct
+	// Type is either the struct type or the pointer to it.
+	Type       types.Type
+	Implements bool
+	Assignable bool
+}
+
+// AssignabilityDiscrepancies returns every struct (or pointer to it) for which
+// types.AssignableTo and types.Implements disagree about iface. The Go spec defines assignability to an
+// interface through implementation, so this is expected to be empty.
+func AssignabilityDiscrepancies(strcts []Struct, iface Interface) []Discrepancy {
+	discrepancies := make([]Discrepancy, 0)
+	for _, strct := range strcts {
+		for _, t := range []types.Type{strct.Obj.Type(), PointerTo(strct.Obj.Type())} {
+			implements := types.Implements(t, iface.Type)
+			assignable := types.AssignableTo(t, iface.Type)
+			if implements != assignable {
+				discrepancies = append(discrepancies, Discrepancy{Struct: strct, Type: t, Implements: implements, Assignable: assignable})
+			}
+		}
+	}
+	return discrepancies
+}
+
+// MethodDoc returns the doc comment of the interface method m. m may be declared in any package
+// reachable from pkgs. The empty string is returned when the declaration can't be found.
+func MethodDoc(pkgs []*packages.Package, m *types.Func) string {
+	if m.Pkg() == nil {
+		return ""
+	}
+
+	var doc string
+	packages.Visit(pkgs, func(pkg *packages.Package) bool {
+		if doc != "" {
+			return false
+		}
+		if pkg.PkgPath != m.Pkg().Path() {
+			return true
+		}
+		for _, f := range pkg.Syntax {
+			ast.Inspect(f, func(n ast.Node) bool {
+				field, ok := n.(*ast.Field)
+				if !ok || doc != "" {
+					return doc == ""
+				}
+				for _, name := range field.Names {
+					if name.Pos() == m.Pos() {
+						if field.Doc != nil {
+							doc = field.Doc.Text()
+						} else if field.Comment != nil {
+							doc = field.Comment.Text()
+						}
+					}
+				}
+				return true
+			})
+		}
+		return false
+	}, nil)
+
+	return doc
+}
+
+// ExportedMethodsOnly returns a synthetic interface with only the exported methods of iface.
+// Unexported interface methods can only be implemented by types of the interface's own package,
+// so dropping them lets types from other packages match on the exported part of the interface.
+func ExportedMethodsOnly(iface *types.Interface) *types.Interface {
+	methods := make([]*types.Func, 0, iface.NumMethods())
+	for i := 0; i < iface.NumMethods(); i++ {
+		if m := iface.Method(i); m.Exported() {
+			methods = append(methods, m)
+		}
+	}
+	return types.NewInterfaceType(methods, nil).Complete()
+}
+
+// ImplementationCost tells how much work implementing iface from scratch takes: the number of methods,
+// including the ones of embedded interfaces, and the sorted distinct types that appear in their parameters and results.
+func ImplementationCost(iface Interface) (methods int, typeNames []string) {
+	seen := make(map[string]bool)
+	typeNames = make([]string, 0)
+	for i := 0; i < iface.Type.NumMethods(); i++ {
+		sig := iface.Type.Method(i).Type().(*types.Signature)
+		for _, tuple := range []*types.Tuple{sig.Params(), sig.Results()} {
+			for j := 0; j < tuple.Len(); j++ {
+				name := types.TypeString(tuple.At(j).Type(), PackageNameQualifier)
+				if !seen[name] {
+					seen[name] = true
+					typeNames = append(typeNames, name)
+				}
+			}
+		}
+	}
+	sort.Strings(typeNames)
+
+	return iface.Type.NumMethods(), typeNames
+}
+
+// EmbeddedProviders returns the types of the embedded fields of strct that promote at least one
+// of the methods strct uses to satisfy iface, sorted by name.
+func EmbeddedProviders(strct Struct, iface Interface) []string {
+	fields, ok := strct.Type.(*types.Struct)
+	if !ok {
+		return nil
+	}
+	ptr := PointerTo(strct.Obj.Type())
+	seen := make(map[string]bool)
+	providers := make([]string, 0)
+	for i := 0; i < iface.Type.NumMethods(); i++ {
+		m := iface.Type.Method(i)
+		_, index, _ := types.LookupFieldOrMethod(ptr, false, m.Pkg(), m.Name())
+		// index has more than one entry when the method is promoted. The first one is the embedded field of strct.
+		if len(index) < 2 || index[0] >= fields.NumFields() {
+			continue
+		}
+		provider := types.TypeString(fields.Field(index[0]).Type(), PackageNameQualifier)
+		if !seen[provider] {
+			seen[provider] = true
+			providers = append(providers, provider)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
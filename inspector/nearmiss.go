@@ -0,0 +1,109 @@
+package inspector
+
+import (
+	"go/types"
+)
+
+// NearMiss is a struct that has some, but not all, of the methods of an interface.
+type NearMiss struct {
+	Struct
+	Missing     []MethodMatch
+	Implemented []MethodMatch
+}
+
+// MethodMatch is an interface method looked up in the method set of a type.
+type MethodMatch struct {
+	Method *types.Func
+	// WrongSignature is set when the type has a method with the right name but a different signature.
+	WrongSignature bool
+	// Have is the method of the type with the wrong signature.
+	Have *types.Func
+}
+
+// FindNearMisses returns the structs from strcts that don't implement iface but have at least one of its methods,
+// possibly with a wrong signature.
+func FindNearMisses(strcts []Struct, iface Interface) []NearMiss {
+	nearMisses := make([]NearMiss, 0)
+	variants := newInterfaceVariants(iface)
+	for _, strct := range strcts {
+		t, ifaceType := instantiate(strct, variants.of(strct))
+		ptr := PointerTo(t)
+		if missing, _ := types.MissingMethod(ptr, ifaceType, true); missing == nil {
+			continue
+		}
+
+		nm := NearMiss{Struct: strct}
+		nm.Missing, nm.Implemented = MatchMethods(ptr, ifaceType)
+		if IsNearMiss(nm.Missing, nm.Implemented) {
+			nearMisses = append(nearMisses, nm)
+		}
+	}
+
+	return nearMisses
+}
+
+// MatchMethods looks up every method of iface in the method set of t and splits them into
+// the ones t is missing, possibly because of a wrong signature, and the ones it implements.
+func MatchMethods(t types.Type, iface *types.Interface) (missing, implemented []MethodMatch) {
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		obj, _, _ := types.LookupFieldOrMethod(t, false, m.Pkg(), m.Name())
+		fn, ok := obj.(*types.Func)
+		switch {
+		case ok && types.Identical(fn.Type(), m.Type()):
+			implemented = append(implemented, MethodMatch{Method: m})
+		case ok:
+			missing = append(missing, MethodMatch{Method: m, WrongSignature: true, Have: fn})
+		default:
+			missing = append(missing, MethodMatch{Method: m})
+		}
+	}
+	return missing, implemented
+}
+
+// IsNearMiss reports whether a type with the given method matches has at least one of the methods by name.
+func IsNearMiss(missing, implemented []MethodMatch) bool {
+	if len(implemented) > 0 {
+		return true
+	}
+	for _, m := range missing {
+		if m.WrongSignature {
+			return true
+		}
+	}
+	return false
+}
+
+// Explanation tells why a type does or doesn't implement an interface.
+type Explanation struct {
+	Struct
+	// Receiver is set when the type implements the interface.
+	Receiver ReceiverKind
+	// Missing are the methods that neither the type nor the pointer to it has, or has with a wrong signature.
+	Missing []MethodMatch
+	// PointerOnly are the methods declared with a pointer receiver, which the value lacks.
+	PointerOnly []*types.Func
+}
+
+// Explain checks strct against iface with types.MissingMethod and lists what it lacks, if anything.
+func Explain(strct Struct, iface Interface) Explanation {
+	e := Explanation{Struct: strct}
+	t, ifaceType := instantiate(strct, newInterfaceVariants(iface).of(strct))
+	if missing, _ := types.MissingMethod(t, ifaceType, true); missing == nil {
+		e.Receiver = ValueReceiver
+		return e
+	}
+	if missing, _ := types.MissingMethod(PointerTo(t), ifaceType, true); missing == nil {
+		e.Receiver = PointerReceiver
+	}
+
+	e.Missing, _ = MatchMethods(PointerTo(t), ifaceType)
+	valueMissing, _ := MatchMethods(t, ifaceType)
+	for _, m := range valueMissing {
+		obj, _, _ := types.LookupFieldOrMethod(PointerTo(t), false, m.Method.Pkg(), m.Method.Name())
+		if fn, ok := obj.(*types.Func); ok && types.Identical(fn.Type(), m.Method.Type()) {
+			e.PointerOnly = append(e.PointerOnly, fn)
+		}
+	}
+	return e
+}
@@ -0,0 +1,75 @@
+package inspector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Platform is a target operating system and architecture, like linux/amd64.
+type Platform struct {
+	GOOS   string
+	GOARCH string
+}
+
+func (p Platform) String() string {
+	return p.GOOS + "/" + p.GOARCH
+}
+
+// Env returns env with GOOS and GOARCH set to p.
+func (p Platform) Env(env []string) []string {
+	return append(append([]string(nil), env...), "GOOS="+p.GOOS, "GOARCH="+p.GOARCH)
+}
+
+// DefaultPlatforms are the platforms checked when none are given, the common first class ports.
+var DefaultPlatforms = []Platform{
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"windows", "amd64"},
+}
+
+// ParsePlatform parses a platform written as GOOS/GOARCH, like linux/amd64.
+func ParsePlatform(s string) (Platform, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+		return Platform{}, fmt.Errorf("invalid platform %q, expected GOOS/GOARCH like linux/amd64", s)
+	}
+	return Platform{GOOS: goos, GOARCH: goarch}, nil
+}
+
+// PlatformImplementer is an implementer found when loading the packages for some platforms.
+type PlatformImplementer struct {
+	Implementer
+	// Platforms are the platforms it implements the interface on, in the order they were checked.
+	Platforms []Platform
+}
+
+// MergePlatformImplementers merges the implementers found for every platform. Implementers are the same if they
+// have the same qualified name and position, so types declared in files for different platforms, like
+// file_linux.go and file_windows.go, are kept apart. The result is ordered by package path and position.
+func MergePlatformImplementers(platforms []Platform, impls [][]Implementer) []PlatformImplementer {
+	merged := make([]PlatformImplementer, 0)
+	index := make(map[string]int)
+	for i, platformImpls := range impls {
+		for _, impl := range platformImpls {
+			key := QualifiedName(impl.Obj) + " " + impl.PositionString()
+			j, ok := index[key]
+			if !ok {
+				j = len(merged)
+				index[key] = j
+				merged = append(merged, PlatformImplementer{Implementer: impl})
+			}
+			merged[j].Platforms = append(merged[j].Platforms, platforms[i])
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].PkgPath != merged[j].PkgPath {
+			return merged[i].PkgPath < merged[j].PkgPath
+		}
+		return positionLess(merged[i].Position, merged[j].Position)
+	})
+	return merged
+}
@@ -0,0 +1,100 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Query describes which packages to load and which interface to look up in them.
+type Query struct {
+	// Patterns are the packages to scan, in the package pattern syntax of the go command. Defaults to ./...
+	Patterns []string
+	// Dir is the directory the go command runs in. Defaults to the current directory.
+	Dir string
+
+	// Interface is the fully qualified interface, like io.Reader or github.com/me/proj/pkg/cmd.Stringer.
+	// Its package is loaded even if none of the scanned packages depends on it.
+	Interface string
+	// Package, PackageDir and InterfaceName find the interface by the name of its package and a part of
+	// its import path instead, when Interface is empty. See FindInterface.
+	Package       string
+	PackageDir    string
+	InterfaceName string
+
+	// BuildFlags and Env are passed to the go command, e.g. -tags=integration or GOOS=windows.
+	BuildFlags []string
+	Env        []string
+	// Tests also loads the _test.go files.
+	Tests bool
+	// Jobs is the number of goroutines scanning the packages and checking the types. Defaults to GOMAXPROCS.
+	Jobs int
+	// ExportData loads the packages with ExportDataLoadMode instead of LoadMode.
+	ExportData bool
+}
+
+// Load loads the packages of q with LoadMode, or ExportDataLoadMode if q.ExportData is set. With q.Tests, only the test variant of every package is kept, see PreferTestVariants.
+func Load(ctx context.Context, q Query) ([]*packages.Package, error) {
+	patterns := q.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	if q.Interface != "" {
+		pkgPath, _, err := ParseRef(q.Interface)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(append([]string(nil), patterns...), pkgPath)
+	}
+
+	mode := LoadMode
+	if q.ExportData {
+		mode = ExportDataLoadMode
+	}
+	cfg := &packages.Config{
+		Mode:       mode,
+		Context:    ctx,
+		Dir:        q.Dir,
+		BuildFlags: q.BuildFlags,
+		Env:        q.Env,
+		Tests:      q.Tests,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+	if q.Tests {
+		pkgs = PreferTestVariants(pkgs)
+	}
+	return pkgs, nil
+}
+
+// FindImplementers loads the packages of q and returns the types among them that implement the interface of q.
+// Vendored packages aren't scanned.
+func FindImplementers(ctx context.Context, q Query) ([]Implementer, error) {
+	pkgs, err := Load(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return findImplementers(pkgs, q)
+}
+
+// findImplementers returns the types among pkgs, loaded for q, that implement the interface of q.
+func findImplementers(pkgs []*packages.Package, q Query) ([]Implementer, error) {
+	var err error
+	var iface Interface
+	scanPkgs := FilterVendorPackages(pkgs)
+	if q.Interface != "" {
+		iface, err = FindInterfaceByRef(pkgs, q.Interface)
+		pkgPath, _, _ := ParseRef(q.Interface)
+		scanPkgs = DropExternalPackage(scanPkgs, pkgPath)
+	} else {
+		iface, err = FindInterface(pkgs, q.Package, q.PackageDir, q.InterfaceName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParallelImplementers(FindStructs(scanPkgs, q.Jobs), iface, q.Jobs), nil
+}
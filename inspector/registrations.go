@@ -0,0 +1,74 @@
+package inspector
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Registration is a call to a registration function whose argument implements the interface.
+type Registration struct {
+	Type     types.Type
+	CallSite token.Position
+}
+
+// FindRegistrations looks for calls to one of the functions named in funcNames, either in init functions or
+// in the initializers of package level variables, and returns the arguments whose type implements iface.
+// Calls are matched by the name of the called function or method only, so register(x) and plugins.register(x)
+// both match "register".
+func FindRegistrations(pkgs []*packages.Package, funcNames []string, iface Interface) []Registration {
+	names := make(map[string]bool, len(funcNames))
+	for _, name := range funcNames {
+		names[name] = true
+	}
+
+	registrations := make([]Registration, 0)
+	inspectCalls := func(pkg *packages.Package, root ast.Node) {
+		ast.Inspect(root, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !names[calledName(call)] {
+				return true
+			}
+			for _, arg := range call.Args {
+				t := pkg.TypesInfo.TypeOf(arg)
+				if t == nil || types.IsInterface(t) || !types.Implements(t, iface.Type) {
+					continue
+				}
+				registrations = append(registrations, Registration{Type: t, CallSite: pkg.Fset.Position(call.Pos())})
+			}
+			return true
+		})
+	}
+
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			for _, decl := range f.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					if decl.Recv == nil && decl.Name.Name == "init" && decl.Body != nil {
+						inspectCalls(pkg, decl.Body)
+					}
+				case *ast.GenDecl:
+					if decl.Tok == token.VAR {
+						inspectCalls(pkg, decl)
+					}
+				}
+			}
+		}
+	}
+
+	return registrations
+}
+
+// calledName returns the name of the function or method call calls, or the empty string for other callees.
+func calledName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
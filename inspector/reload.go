@@ -0,0 +1,185 @@
+package inspector
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/types"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// reloadChanged type checks the packages with one of the changed files again, among pkgs and their dependencies,
+// along with the packages importing them, directly or not. The other packages are kept as they are, with their
+// types. ok is false if the changes can't be handled that way and the packages must be loaded from scratch:
+// a changed file that isn't a Go file of a package, like go.mod or a directory whose files were added or
+// removed, a package using cgo, or a changed import.
+func reloadChanged(pkgs []*packages.Package, changed map[string]bool) (reloaded []*packages.Package, ok bool) {
+	// the packages ordered so that every package comes after the ones it imports
+	var all []*packages.Package
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		all = append(all, pkg)
+	})
+
+	dirty := make(map[*packages.Package]bool)
+	found := make(map[string]bool)
+	for _, pkg := range all {
+		for _, file := range pkg.CompiledGoFiles {
+			if changed[file] {
+				dirty[pkg] = true
+				found[file] = true
+			}
+		}
+	}
+	if len(found) != len(changed) {
+		return nil, false
+	}
+
+	// the new versions of the changed packages and of the ones importing them
+	newer := make(map[*packages.Package]*packages.Package)
+	for _, pkg := range all {
+		affected := dirty[pkg]
+		for _, imp := range pkg.Imports {
+			affected = affected || newer[imp] != nil
+		}
+		if !affected {
+			continue
+		}
+		if pkg.Fset == nil || pkg.TypesInfo == nil || !slices.Equal(pkg.GoFiles, pkg.CompiledGoFiles) {
+			return nil, false
+		}
+		checked, ok := recheck(pkg, newer, dirty[pkg])
+		if !ok {
+			return nil, false
+		}
+		newer[pkg] = checked
+	}
+
+	reloaded = make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if n := newer[pkg]; n != nil {
+			pkg = n
+		}
+		reloaded = append(reloaded, pkg)
+	}
+	return reloaded, true
+}
+
+// recheck parses the files of pkg again and type checks them, against the new versions of its imports
+// in newer, like packages.Load does. ok is false if a changed file, one of a dirty package, changed
+// its imports.
+func recheck(pkg *packages.Package, newer map[*packages.Package]*packages.Package, dirty bool) (checked *packages.Package, ok bool) {
+	var errs []packages.Error
+	// the errors of go list are kept, the parse and type errors are found again
+	for _, err := range pkg.Errors {
+		if err.Kind != packages.ParseError && err.Kind != packages.TypeError {
+			errs = append(errs, err)
+		}
+	}
+	var typeErrs []types.Error
+
+	files := make([]*ast.File, 0, len(pkg.CompiledGoFiles))
+	for _, filename := range pkg.CompiledGoFiles {
+		f, err := parser.ParseFile(pkg.Fset, filename, nil, parser.AllErrors|parser.ParseComments)
+		if f == nil {
+			// removed since, the go command has to tell what the package is made of now
+			return nil, false
+		}
+		if list, isList := err.(scanner.ErrorList); isList {
+			for _, e := range list {
+				errs = append(errs, packages.Error{Pos: e.Pos.String(), Msg: e.Msg, Kind: packages.ParseError})
+			}
+		}
+		files = append(files, f)
+	}
+	if dirty && !sameImports(files, pkg.Imports) {
+		return nil, false
+	}
+
+	imports := make(map[string]*packages.Package, len(pkg.Imports))
+	for path, imp := range pkg.Imports {
+		if n := newer[imp]; n != nil {
+			imp = n
+		}
+		imports[path] = imp
+	}
+
+	info := &types.Info{
+		Types:        make(map[ast.Expr]types.TypeAndValue),
+		Defs:         make(map[*ast.Ident]types.Object),
+		Uses:         make(map[*ast.Ident]types.Object),
+		Implicits:    make(map[ast.Node]types.Object),
+		Instances:    make(map[*ast.Ident]types.Instance),
+		Scopes:       make(map[ast.Node]*types.Scope),
+		Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+		FileVersions: make(map[*ast.File]string),
+	}
+	conf := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			imp := imports[path]
+			if imp == nil || imp.Types == nil {
+				return nil, fmt.Errorf("no metadata for %s", path)
+			}
+			return imp.Types, nil
+		}),
+		Error: func(err error) {
+			if e, isTypeErr := err.(types.Error); isTypeErr {
+				typeErrs = append(typeErrs, e)
+				errs = append(errs, packages.Error{Pos: e.Fset.Position(e.Pos).String(), Msg: e.Msg, Kind: packages.TypeError})
+			}
+		},
+		Sizes: pkg.TypesSizes,
+	}
+	if pkg.Module != nil && pkg.Module.GoVersion != "" {
+		conf.GoVersion = "go" + pkg.Module.GoVersion
+	}
+	tpkg := types.NewPackage(pkg.PkgPath, pkg.Name)
+	types.NewChecker(conf, pkg.Fset, tpkg, info).Files(files)
+
+	checked = new(packages.Package)
+	*checked = *pkg
+	checked.Imports = imports
+	checked.Syntax = files
+	checked.Types = tpkg
+	checked.TypesInfo = info
+	checked.Errors = errs
+	checked.TypeErrors = typeErrs
+	checked.IllTyped = len(errs) > 0
+	for _, imp := range imports {
+		checked.IllTyped = checked.IllTyped || imp.IllTyped
+	}
+	return checked, true
+}
+
+// sameImports reports whether files import exactly the packages of imports, keyed by their import path.
+func sameImports(files []*ast.File, imports map[string]*packages.Package) bool {
+	paths := make(map[string]bool)
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return false
+			}
+			paths[path] = true
+		}
+	}
+	if len(paths) != len(imports) {
+		return false
+	}
+	for path := range paths {
+		if _, ok := imports[path]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -0,0 +1,171 @@
+package inspector
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Satisfied is an interface implemented by a given type.
+type Satisfied struct {
+	Interface
+	Receiver ReceiverKind
+}
+
+// FindStruct finds the named type typeName in package packageName. Like FindStructs, it accepts any named type
+// but an interface.
+func FindStruct(pkgs []*packages.Package, packageName, packageDirectory, typeName string) (Struct, error) {
+	thePackage, err := findPackage(pkgs, packageName, packageDirectory)
+	if err != nil {
+		return Struct{}, err
+	}
+
+	for _, strct := range packageStructs(thePackage) {
+		if strct.Obj.Name() == typeName {
+			return strct, nil
+		}
+	}
+	return Struct{}, fmt.Errorf("no such type %q in package %q", typeName, packageName)
+}
+
+// FindStructByRef finds the named type referenced by ref, like github.com/me/proj/pkg/db.Store.
+// The import path may be shortened to its trailing elements, like pkg/db.Store, as long as only one
+// loaded package matches.
+func FindStructByRef(pkgs []*packages.Package, ref string) (Struct, error) {
+	pkgPath, typeName, err := ParseRef(ref)
+	if err != nil {
+		return Struct{}, err
+	}
+
+	thePackage, err := matchPackage(pkgs, pkgPath, typeName)
+	if err != nil {
+		return Struct{}, err
+	}
+
+	for _, strct := range packageStructs(thePackage) {
+		if strct.Obj.Name() == typeName {
+			return strct, nil
+		}
+	}
+	return Struct{}, fmt.Errorf("no such type %q in package %q", typeName, thePackage.PkgPath)
+}
+
+// matchPackage finds the package among pkgs and their dependencies whose import path is pkgPath or ends with it,
+// to look name up in. Exactly one import path has to match. Loaded with packages.Config.Tests, a package and its
+// test variant share their import path: the package on its own is preferred, like by FindInterfaceByRef, unless
+// only the test variant declares name, like a type of a _test.go file.
+func matchPackage(pkgs []*packages.Package, pkgPath, name string) (*packages.Package, error) {
+	matches := make([]*packages.Package, 0)
+	packages.Visit(pkgs, func(pkg *packages.Package) bool {
+		if pkg.PkgPath == pkgPath || strings.HasSuffix(pkg.PkgPath, "/"+pkgPath) {
+			matches = append(matches, pkg)
+		}
+		return true
+	}, nil)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("couldn't find a package with the import path %q", pkgPath)
+	}
+	for _, pkg := range matches[1:] {
+		if pkg.PkgPath != matches[0].PkgPath {
+			return nil, fmt.Errorf("%q matches several packages, like %q and %q", pkgPath, matches[0].PkgPath, pkg.PkgPath)
+		}
+	}
+
+	declares := func(pkg *packages.Package) bool {
+		return pkg.Types != nil && pkg.Types.Scope().Lookup(name) != nil
+	}
+	for _, pkg := range matches {
+		if pkg.ID == pkg.PkgPath && declares(pkg) {
+			return pkg, nil
+		}
+	}
+	for _, pkg := range matches {
+		if declares(pkg) {
+			return pkg, nil
+		}
+	}
+	return matches[0], nil
+}
+
+// StdlibPackages returns the packages of the standard library that pkgs depend on, directly or not.
+// Internal and vendored packages are left out since their interfaces can't be used outside of the standard library.
+func StdlibPackages(pkgs []*packages.Package) []*packages.Package {
+	std := make([]*packages.Package, 0)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		// the first element of the import paths of the standard library has no dot, unlike module paths
+		first, _, _ := strings.Cut(pkg.PkgPath, "/")
+		internal := first == "internal" || strings.Contains(pkg.PkgPath, "/internal") || first == "vendor"
+		if pkg.Module == nil && !strings.Contains(first, ".") && !internal && pkg.Types != nil {
+			std = append(std, pkg)
+		}
+	})
+	return std
+}
+
+// FindAliases finds the type aliases declared at the top level of pkgs, like type Store = postgresStore.
+// FindStructs skips them since the type they stand for is found on its own. Aliases of interfaces are left out.
+func FindAliases(pkgs []*packages.Package) []Struct {
+	aliases := make([]Struct, 0)
+	for _, pkg := range pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !obj.IsAlias() || typeKind(obj.Type().Underlying()) == "" {
+				continue
+			}
+			aliases = append(aliases, Struct{
+				Obj:      obj,
+				Type:     obj.Type().Underlying(),
+				Kind:     "alias",
+				Name:     obj.Name(),
+				PkgPath:  pkg.PkgPath,
+				Position: pkg.Fset.Position(obj.Pos())})
+		}
+	}
+	return SortStructs(aliases)
+}
+
+// FindInterfaces finds all interfaces declared at the top level of pkgs, ordered by package path and position.
+// Generic interfaces are left out since they can only be implemented once instantiated.
+func FindInterfaces(pkgs []*packages.Package) []Interface {
+	ifaces := make([]Interface, 0)
+	for _, pkg := range pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			iface, ok := interfaceOf(pkg, obj)
+			if ok && iface.TypeParams.Len() == 0 {
+				ifaces = append(ifaces, iface)
+			}
+		}
+	}
+
+	sort.SliceStable(ifaces, func(i, j int) bool {
+		if ifaces[i].Pkg.Path() != ifaces[j].Pkg.Path() {
+			return ifaces[i].Pkg.Path() < ifaces[j].Pkg.Path()
+		}
+		return positionLess(ifaces[i].Position, ifaces[j].Position)
+	})
+	return ifaces
+}
+
+// SatisfiedInterfaces returns the interfaces of ifaces that strct implements. Interfaces without methods
+// are left out since every type implements them.
+func SatisfiedInterfaces(strct Struct, ifaces []Interface) []Satisfied {
+	satisfied := make([]Satisfied, 0)
+	for _, iface := range ifaces {
+		if iface.Type.Empty() {
+			continue
+		}
+		for _, impl := range Implementers([]Struct{strct}, iface) {
+			satisfied = append(satisfied, Satisfied{Interface: iface, Receiver: impl.Receiver})
+		}
+	}
+	return satisfied
+}
@@ -0,0 +1,165 @@
+package inspector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Session serves several lookups from one load of the packages of a query. The packages are only loaded again
+// once one of the files of the packages of the main module, or their directories, changed. If only the content
+// of Go files changed, only their packages and the ones importing them are type checked again, the types of the
+// others are kept. A Session is safe for concurrent use.
+type Session struct {
+	query Query
+
+	mu    sync.Mutex
+	pkgs  []*packages.Package
+	files map[string]fileStamp
+	// the size of the file set of the packages when they were last loaded from scratch
+	loaded int
+}
+
+// fileStamp is what a change of a file or a directory is detected by. A directory changes when its Go files
+// are added, removed or renamed, not when they're written to.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	goFiles string
+}
+
+// NewSession returns a session loading the packages of q. Nothing is loaded until they are first needed.
+func NewSession(q Query) *Session {
+	return &Session{query: q}
+}
+
+// Packages returns the packages of the session's query, see Load. They are loaded on the first call and
+// again when a file they were loaded from changed since.
+func (s *Session) Packages(ctx context.Context) ([]*packages.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pkgs != nil {
+		changed := s.changed()
+		if len(changed) == 0 {
+			return s.pkgs, nil
+		}
+		// the files parsed again are added to the file set the packages share, which is replaced by loading them
+		// from scratch once it doubled
+		if !s.query.ExportData && fsetSize(s.pkgs) <= 2*s.loaded {
+			if pkgs, ok := reloadChanged(s.pkgs, changed); ok {
+				s.pkgs, s.files = pkgs, stampFiles(pkgs)
+				return pkgs, nil
+			}
+		}
+	}
+	return s.load(ctx)
+}
+
+// Reload loads the packages of the session's query from scratch, like when packages were added that none of the
+// loaded ones imports.
+func (s *Session) Reload(ctx context.Context) ([]*packages.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(ctx)
+}
+
+func (s *Session) load(ctx context.Context) ([]*packages.Package, error) {
+	pkgs, err := Load(ctx, s.query)
+	if err != nil {
+		return nil, err
+	}
+	s.pkgs, s.files, s.loaded = pkgs, stampFiles(pkgs), fsetSize(pkgs)
+	return pkgs, nil
+}
+
+// fsetSize returns the size of the file set of pkgs, the bytes of all the files parsed into it.
+func fsetSize(pkgs []*packages.Package) int {
+	if len(pkgs) == 0 || pkgs[0].Fset == nil {
+		return 0
+	}
+	return pkgs[0].Fset.Base()
+}
+
+// FindImplementers is like the function FindImplementers, but on the packages of the session.
+// The interface is taken from the session's query if iface is empty.
+func (s *Session) FindImplementers(ctx context.Context, iface string) ([]Implementer, error) {
+	pkgs, err := s.Packages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := s.query
+	if iface != "" {
+		q.Interface = iface
+	}
+	return findImplementers(pkgs, q)
+}
+
+// changed returns the stamped files and directories that changed.
+func (s *Session) changed() map[string]bool {
+	changed := make(map[string]bool)
+	for path, stamp := range s.files {
+		if current, err := stampFile(path); err != nil || current != stamp {
+			changed[path] = true
+		}
+	}
+	return changed
+}
+
+// stampFiles stamps the files of the packages of the main module, their directories and the go.mod file.
+// Adding a file to a directory changes the directory. The other modules and the standard library don't change.
+func stampFiles(pkgs []*packages.Package) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	stamp := func(path string) {
+		if _, ok := stamps[path]; ok {
+			return
+		}
+		if current, err := stampFile(path); err == nil {
+			stamps[path] = current
+		}
+	}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Module == nil || !pkg.Module.Main {
+			return
+		}
+		if pkg.Module.GoMod != "" {
+			stamp(pkg.Module.GoMod)
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+			for _, file := range files {
+				stamp(file)
+				stamp(filepath.Dir(file))
+			}
+		}
+	})
+	return stamps
+}
+
+func stampFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	if !info.IsDir() {
+		return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+	}
+
+	// editors often write a file by renaming a new one over it, which changes the modification time of the directory
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	var goFiles strings.Builder
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasSuffix(name, ".go") && !entry.IsDir() {
+			goFiles.WriteString(name)
+			goFiles.WriteByte('/')
+		}
+	}
+	return fileStamp{goFiles: goFiles.String()}, nil
+}
@@ -0,0 +1,155 @@
+package inspector
+
+import (
+	"context"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typesOf returns the types of the package with the import path pkgPath among pkgs and their dependencies.
+func typesOf(t *testing.T, pkgs []*packages.Package, pkgPath string) *types.Package {
+	t.Helper()
+	var found *types.Package
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.PkgPath == pkgPath {
+			found = pkg.Types
+		}
+	})
+	if found == nil {
+		t.Fatalf("no package %s", pkgPath)
+	}
+	return found
+}
+
+func TestSessionReloadsChangedPackages(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"foo/foo.go": "package foo\n\ntype Doer interface{ Do() }\n",
+		"bar/bar.go": "package bar\n\ntype Bar struct{}\n\nfunc (Bar) Do() {}\n",
+		"baz/baz.go": "package baz\n\nimport \"example.com/m/foo\"\n\ntype Baz struct{ foo.Doer }\n",
+	})
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	s := NewSession(Query{Dir: dir, Interface: "example.com/m/foo.Doer"})
+
+	implementers := func() []string {
+		t.Helper()
+		impls, err := s.FindImplementers(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return names(impls)
+	}
+	if got, want := implementers(), []string{"Bar", "Baz"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	pkgs, err := s.Packages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foo, bar, baz := typesOf(t, pkgs, "example.com/m/foo"), typesOf(t, pkgs, "example.com/m/bar"), typesOf(t, pkgs, "example.com/m/baz")
+
+	// only bar is type checked again
+	write("bar/bar.go", "package bar\n\ntype Bar struct{}\n\nfunc (Bar) Done() {}\n")
+	if got, want := implementers(), []string{"Baz"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after changing bar: got %v, want %v", got, want)
+	}
+	pkgs, err = s.Packages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typesOf(t, pkgs, "example.com/m/bar") == bar {
+		t.Error("bar wasn't type checked again")
+	}
+	if typesOf(t, pkgs, "example.com/m/foo") != foo || typesOf(t, pkgs, "example.com/m/baz") != baz {
+		t.Error("the unchanged packages were type checked again")
+	}
+
+	// baz imports foo, so both are type checked again
+	write("foo/foo.go", "package foo\n\ntype Doer interface{ Done() }\n")
+	if got, want := implementers(), []string{"Bar", "Baz"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after changing foo: got %v, want %v", got, want)
+	}
+	pkgs, err = s.Packages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typesOf(t, pkgs, "example.com/m/foo") == foo || typesOf(t, pkgs, "example.com/m/baz") == baz {
+		t.Error("foo and the package importing it weren't type checked again")
+	}
+
+	// a new import needs the go command, everything is loaded again
+	bar = typesOf(t, pkgs, "example.com/m/bar")
+	write("baz/baz.go", "package baz\n\nimport (\n\t\"example.com/m/bar\"\n\t\"example.com/m/foo\"\n)\n\ntype Baz struct{ foo.Doer }\n\nvar _ bar.Bar\n")
+	pkgs, err = s.Packages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("the packages don't compile")
+	}
+	if typesOf(t, pkgs, "example.com/m/bar") == bar {
+		t.Error("the packages weren't loaded again")
+	}
+
+	// type errors are reported like by Load
+	write("bar/bar.go", "package bar\n\ntype Bar struct{ x undefined }\n")
+	pkgs, err = s.Packages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []packages.Error
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.PkgPath == "example.com/m/bar" {
+			errs = pkg.Errors
+		}
+	})
+	if len(errs) != 1 || errs[0].Kind != packages.TypeError || errs[0].Msg != "undefined: undefined" {
+		t.Errorf("got errors %v, want undefined: undefined", errs)
+	}
+}
+
+func TestSessionBoundsFileSet(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"foo/foo.go": "package foo\n\ntype Doer interface{ Do() }\n",
+		"bar/bar.go": "package bar\n\ntype Bar struct{}\n\nfunc (Bar) Do() {}\n",
+	})
+	ctx := context.Background()
+	s := NewSession(Query{Dir: dir, Interface: "example.com/m/foo.Doer"})
+	pkgs, err := s.Packages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foo := typesOf(t, pkgs, "example.com/m/foo")
+
+	reloaded := false
+	for i := range 20 {
+		// a different size every time, a change is seen even within the resolution of the modification time
+		content := "package bar\n\ntype Bar struct{}\n\nfunc (Bar) Do() {}\n\n// " + strings.Repeat("x", i) + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "bar", "bar.go"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if pkgs, err = s.Packages(ctx); err != nil {
+			t.Fatal(err)
+		}
+		// at most one change more than twice the size of the last load from scratch
+		if size := fsetSize(pkgs); size > 2*s.loaded+len(content)+1 {
+			t.Fatalf("after %d changes: the file set grew to %d from %d", i+1, size, s.loaded)
+		}
+		// foo is only type checked again when everything is loaded from scratch
+		reloaded = reloaded || typesOf(t, pkgs, "example.com/m/foo") != foo
+	}
+	if !reloaded {
+		t.Error("the packages were never loaded from scratch")
+	}
+}
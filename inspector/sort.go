@@ -0,0 +1,139 @@
+package inspector
+
+import (
+	"container/heap"
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// lessFuncs are the orders SortAndLimit accepts.
+var lessFuncs = map[string]func(a, b Implementer) bool{
+	"position": byPosition,
+	// ties keep their order, so the types of a file stay in the order they were found in
+	"file": func(a, b Implementer) bool {
+		return a.Position.Filename < b.Position.Filename
+	},
+	"name": func(a, b Implementer) bool {
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return positionLess(a.Position, b.Position)
+	},
+	"package": func(a, b Implementer) bool {
+		if a.PkgPath != b.PkgPath {
+			return a.PkgPath < b.PkgPath
+		}
+		return positionLess(a.Position, b.Position)
+	},
+}
+
+func byPosition(a, b Implementer) bool {
+	return positionLess(a.Position, b.Position)
+}
+
+func positionLess(a, b token.Position) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// SortAndLimit orders strcts by the key sortBy, if any, and keeps the first limit of them when limit is positive.
+// Ties keep the order of strcts. When both are given only a heap of limit elements is maintained instead of
+// sorting all of strcts. strcts isn't modified.
+func SortAndLimit(strcts []Implementer, sortBy string, limit int) ([]Implementer, error) {
+	if sortBy == "" {
+		if limit > 0 && len(strcts) > limit {
+			return strcts[:limit], nil
+		}
+		return strcts, nil
+	}
+
+	less, ok := lessFuncs[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort key %q", sortBy)
+	}
+	if limit <= 0 || len(strcts) <= limit {
+		sorted := append([]Implementer(nil), strcts...)
+		sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+		return sorted, nil
+	}
+	return topN(strcts, limit, less), nil
+}
+
+// topN returns the n smallest structs of strcts according to less, in order. Ties are broken by the index in
+// strcts, which makes the result that of a stable sort.
+func topN(strcts []Implementer, n int, less func(a, b Implementer) bool) []Implementer {
+	// a max heap: the root is the largest of the n smallest structs seen so far
+	h := &strctHeap{less: func(a, b indexedImplementer) bool {
+		if less(a.Implementer, b.Implementer) {
+			return false
+		}
+		return less(b.Implementer, a.Implementer) || a.index > b.index
+	}}
+	for i, strct := range strcts {
+		item := indexedImplementer{Implementer: strct, index: i}
+		if h.Len() < n {
+			heap.Push(h, item)
+			continue
+		}
+		// a later struct has a larger index, so it only replaces the root if it's strictly smaller
+		if less(strct, h.items[0].Implementer) {
+			h.items[0] = item
+			heap.Fix(h, 0)
+		}
+	}
+
+	result := make([]Implementer, h.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(h).(indexedImplementer).Implementer
+	}
+	return result
+}
+
+// indexedImplementer is an implementer with its index in the input of topN.
+type indexedImplementer struct {
+	Implementer
+	index int
+}
+
+type strctHeap struct {
+	items []indexedImplementer
+	less  func(a, b indexedImplementer) bool
+}
+
+func (h *strctHeap) Len() int           { return len(h.items) }
+func (h *strctHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *strctHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *strctHeap) Push(x any)         { h.items = append(h.items, x.(indexedImplementer)) }
+func (h *strctHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// SortStructs orders strcts by package path, filename, line and column, and drops the types that appear more
+// than once. The same type is loaded more than once when a package is reachable through several loaded packages,
+// like a package and the test variant of it, in which case the copies have distinct objects but the same position.
+func SortStructs(strcts []Struct) []Struct {
+	sort.SliceStable(strcts, func(i, j int) bool {
+		if strcts[i].PkgPath != strcts[j].PkgPath {
+			return strcts[i].PkgPath < strcts[j].PkgPath
+		}
+		return positionLess(strcts[i].Position, strcts[j].Position)
+	})
+
+	deduped := make([]Struct, 0, len(strcts))
+	for _, strct := range strcts {
+		if n := len(deduped); n > 0 && deduped[n-1].PkgPath == strct.PkgPath && deduped[n-1].Name == strct.Name &&
+			deduped[n-1].Position == strct.Position {
+			continue
+		}
+		deduped = append(deduped, strct)
+	}
+	return deduped
+}
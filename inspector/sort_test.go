@@ -0,0 +1,95 @@
+package inspector
+
+import (
+	"go/token"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSortAndLimit(t *testing.T) {
+	impl := func(name, pkgPath, file string, line int) Implementer {
+		return Implementer{Struct: Struct{Name: name, PkgPath: pkgPath, Position: token.Position{Filename: file, Line: line}}}
+	}
+	strcts := []Implementer{
+		impl("b", "m/y", "y/y.go", 3),
+		impl("a", "m/y", "y/y.go", 9),
+		impl("c", "m/x", "x/x.go", 7),
+		impl("a", "m/x", "x/x.go", 1),
+		impl("d", "m/z", "a/z.go", 5),
+	}
+	label := func(impls []Implementer) []string {
+		result := make([]string, 0, len(impls))
+		for _, impl := range impls {
+			result = append(result, impl.PkgPath+"."+impl.Name)
+		}
+		return result
+	}
+
+	for _, tt := range []struct {
+		sortBy string
+		limit  int
+		want   []string
+	}{
+		{"", 0, []string{"m/y.b", "m/y.a", "m/x.c", "m/x.a", "m/z.d"}},
+		{"", 2, []string{"m/y.b", "m/y.a"}},
+		{"name", 0, []string{"m/x.a", "m/y.a", "m/y.b", "m/x.c", "m/z.d"}},
+		{"name", 3, []string{"m/x.a", "m/y.a", "m/y.b"}},
+		{"position", 0, []string{"m/z.d", "m/x.a", "m/x.c", "m/y.b", "m/y.a"}},
+		{"position", 2, []string{"m/z.d", "m/x.a"}},
+		// the types of a file keep their order
+		{"file", 0, []string{"m/z.d", "m/x.c", "m/x.a", "m/y.b", "m/y.a"}},
+		{"file", 3, []string{"m/z.d", "m/x.c", "m/x.a"}},
+		{"package", 0, []string{"m/x.a", "m/x.c", "m/y.b", "m/y.a", "m/z.d"}},
+		{"package", 10, []string{"m/x.a", "m/x.c", "m/y.b", "m/y.a", "m/z.d"}},
+	} {
+		got, err := SortAndLimit(strcts, tt.sortBy, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(label(got), tt.want) {
+			t.Errorf("SortAndLimit(%q, %d) = %v, want %v", tt.sortBy, tt.limit, label(got), tt.want)
+		}
+	}
+
+	if _, err := SortAndLimit(strcts, "size", 0); err == nil {
+		t.Error("SortAndLimit accepted the unknown key size")
+	}
+	if got := label(strcts); got[0] != "m/y.b" {
+		t.Errorf("SortAndLimit modified its argument: %v", got)
+	}
+}
+
+// TestSortAndLimitHeap checks the heap kept with a limit against a full stable sort truncated to the limit,
+// on inputs with many ties.
+func TestSortAndLimitHeap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for run := 0; run < 200; run++ {
+		strcts := make([]Implementer, rnd.Intn(40))
+		for i := range strcts {
+			strcts[i] = Implementer{Struct: Struct{
+				Name:     string(rune('a' + rnd.Intn(3))),
+				PkgPath:  "m/" + string(rune('x'+rnd.Intn(3))),
+				Position: token.Position{Filename: string(rune('a'+rnd.Intn(3))) + ".go", Line: rnd.Intn(4) + 1, Column: i},
+			}}
+		}
+		for sortBy, less := range lessFuncs {
+			want := append([]Implementer(nil), strcts...)
+			sort.SliceStable(want, func(i, j int) bool { return less(want[i], want[j]) })
+			for limit := 1; limit <= len(strcts)+1; limit++ {
+				got, err := SortAndLimit(strcts, sortBy, limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				truncated := want
+				if limit < len(want) {
+					truncated = want[:limit]
+				}
+				if !reflect.DeepEqual(got, truncated) {
+					t.Fatalf("SortAndLimit(%q, %d) of %v = %v, want %v", sortBy, limit, strcts, got, truncated)
+				}
+			}
+		}
+	}
+}
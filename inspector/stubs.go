@@ -0,0 +1,92 @@
+package inspector
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// FuncDecls maps the position of a function or method name to its declaration.
+type FuncDecls map[token.Pos]*ast.FuncDecl
+
+// IndexFuncDecls indexes the function declarations of pkgs and of all their dependencies.
+func IndexFuncDecls(pkgs []*packages.Package) FuncDecls {
+	decls := make(FuncDecls)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, f := range pkg.Syntax {
+			for _, decl := range f.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok {
+					decls[fn.Name.Pos()] = fn
+				}
+			}
+		}
+	})
+	return decls
+}
+
+// IsStubImplementer reports whether every method strct uses to satisfy iface is a stub, see IsStubBody.
+// Methods whose source isn't available are never considered stubs.
+func IsStubImplementer(strct Struct, iface Interface, decls FuncDecls) bool {
+	ptr := PointerTo(strct.Obj.Type())
+	for i := 0; i < iface.Type.NumMethods(); i++ {
+		m := iface.Type.Method(i)
+		obj, _, _ := types.LookupFieldOrMethod(ptr, false, m.Pkg(), m.Name())
+		if obj == nil {
+			return false
+		}
+		decl, ok := decls[obj.Pos()]
+		if !ok || !IsStubBody(decl.Body) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsStubBody reports whether body looks like a placeholder. That is the case when it
+//   - is empty,
+//   - consists of a single panic(...) call or
+//   - consists of a single return statement whose results are all zero values:
+//     nil, 0, "", false or an empty composite literal like T{}.
+func IsStubBody(body *ast.BlockStmt) bool {
+	if body == nil {
+		return false
+	}
+	if len(body.List) == 0 {
+		return true
+	}
+	if len(body.List) > 1 {
+		return false
+	}
+
+	switch stmt := body.List[0].(type) {
+	case *ast.ExprStmt:
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		ident, ok := call.Fun.(*ast.Ident)
+		return ok && ident.Name == "panic"
+	case *ast.ReturnStmt:
+		for _, result := range stmt.Results {
+			if !isZeroValueExpr(result) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func isZeroValueExpr(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name == "nil" || e.Name == "false"
+	case *ast.BasicLit:
+		return e.Value == "0" || e.Value == `""` || e.Value == "``" || e.Value == "0.0"
+	case *ast.CompositeLit:
+		return len(e.Elts) == 0
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package inspector
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ParamInterface is a named interface type together with the number of function parameters declared with it.
+type ParamInterface struct {
+	Obj      *types.TypeName
+	Uses     int
+	Position token.Position
+}
+
+// FindInterfacesUsedAsParams returns the named interfaces that appear as the type of a parameter of
+// a function or method declared in pkgs, most used first.
+func FindInterfacesUsedAsParams(pkgs []*packages.Package) []ParamInterface {
+	found := make(map[*types.TypeName]*ParamInterface)
+	for _, pkg := range pkgs {
+		for _, obj := range pkg.TypesInfo.Defs {
+			fn, ok := obj.(*types.Func)
+			if !ok {
+				continue
+			}
+			params := fn.Type().(*types.Signature).Params()
+			for i := 0; i < params.Len(); i++ {
+				named, ok := types.Unalias(params.At(i).Type()).(*types.Named)
+				if !ok {
+					continue
+				}
+				if _, ok := named.Underlying().(*types.Interface); !ok {
+					continue
+				}
+				typeName := named.Obj()
+				if _, ok := found[typeName]; !ok {
+					found[typeName] = &ParamInterface{Obj: typeName, Position: pkg.Fset.Position(typeName.Pos())}
+				}
+				found[typeName].Uses++
+			}
+		}
+	}
+
+	result := make([]ParamInterface, 0, len(found))
+	for _, p := range found {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Uses != result[j].Uses {
+			return result[i].Uses > result[j].Uses
+		}
+		return QualifiedName(result[i].Obj) < QualifiedName(result[j].Obj)
+	})
+	return result
+}
+
+// UsageKind tells how an interface is used, see Usage.
+type UsageKind string
+
+const (
+	UsageParam      UsageKind = "param"
+	UsageResult     UsageKind = "result"
+	UsageField      UsageKind = "field"
+	UsageVariable   UsageKind = "variable"
+	UsageAssertion  UsageKind = "type assertion"
+	UsageTypeSwitch UsageKind = "type switch"
+	UsageEmbedded   UsageKind = "embedded"
+	// UsageImplementsCheck is a compile time check like var _ Store = (*postgresStore)(nil).
+	UsageImplementsCheck UsageKind = "implements check"
+	// UsageOther is any other use, like a conversion or a type argument.
+	UsageOther UsageKind = "other"
+)
+
+// Usage is a place an interface is referenced at.
+type Usage struct {
+	Obj      *types.TypeName
+	Kind     UsageKind
+	Position token.Position
+}
+
+// FindUsages returns the places the interfaces ifaces are referenced at in the syntax of pkgs, ordered by position.
+func FindUsages(pkgs []*packages.Package, ifaces []Interface) []Usage {
+	// the interfaces are matched by name since a package loaded more than once, like with its test variant,
+	// declares them more than once
+	wanted := make(map[string]bool, len(ifaces))
+	for _, iface := range ifaces {
+		wanted[iface.QualifiedName()] = true
+	}
+
+	usages := make([]Usage, 0)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, f := range pkg.Syntax {
+			// the ancestors of the visited node, the innermost last
+			stack := make([]ast.Node, 0)
+			ast.Inspect(f, func(n ast.Node) bool {
+				if n == nil {
+					stack = stack[:len(stack)-1]
+					return true
+				}
+				if ident, ok := n.(*ast.Ident); ok {
+					if obj, ok := pkg.TypesInfo.Uses[ident].(*types.TypeName); ok && wanted[QualifiedName(obj)] {
+						usages = append(usages, Usage{Obj: obj, Kind: usageKind(ident, stack), Position: pkg.Fset.Position(ident.Pos())})
+					}
+				}
+				stack = append(stack, n)
+				return true
+			})
+		}
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool { return positionLess(usages[i].Position, usages[j].Position) })
+	return usages
+}
+
+// usageKind classifies the reference ident by its innermost ancestor telling how it is used.
+func usageKind(ident *ast.Ident, stack []ast.Node) UsageKind {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.Field:
+			if i < 2 {
+				return UsageOther
+			}
+			switch parent := stack[i-2].(type) {
+			case *ast.FuncType:
+				if parent.Results != nil && stack[i-1] == parent.Results {
+					return UsageResult
+				}
+				return UsageParam
+			case *ast.StructType:
+				return UsageField
+			case *ast.InterfaceType:
+				return UsageEmbedded
+			}
+			return UsageOther
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				if name.Name != "_" {
+					return UsageVariable
+				}
+			}
+			return UsageImplementsCheck
+		case *ast.TypeAssertExpr:
+			if within(ident, n.Type) {
+				return UsageAssertion
+			}
+		case *ast.CaseClause:
+			for _, expr := range n.List {
+				if within(ident, expr) {
+					return UsageTypeSwitch
+				}
+			}
+		case *ast.CallExpr, *ast.CompositeLit, *ast.BlockStmt:
+			return UsageOther
+		}
+	}
+	return UsageOther
+}
+
+func within(ident *ast.Ident, n ast.Node) bool {
+	return n != nil && n.Pos() <= ident.Pos() && ident.End() <= n.End()
+}
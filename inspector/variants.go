@@ -0,0 +1,121 @@
+package inspector
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Loaded with packages.Config.Tests, a package with tests exists twice: on its own and as the test variant compiled
+// with its _test.go files, like foo [foo.test]. The packages loaded for the tests, like the external test package
+// foo_test, see the test variant and the others see the package on its own. The types declared in the two variants
+// are distinct, so the types of one don't implement the interfaces of the other when a method refers to them, like
+// Do(foo.Arg). interfaceVariants checks every type against the interface declared in the variant it sees.
+
+// interfaceVariants looks up an interface in the variants of its package.
+type interfaceVariants struct {
+	iface Interface
+	// seen maps a package to the variant of the interface's package it sees, nil if it doesn't import it
+	seen  map[*types.Package]*types.Package
+	byPkg map[*types.Package]Interface
+}
+
+func newInterfaceVariants(iface Interface) *interfaceVariants {
+	return &interfaceVariants{
+		iface: iface,
+		seen:  make(map[*types.Package]*types.Package),
+		byPkg: make(map[*types.Package]Interface),
+	}
+}
+
+// of returns the interface strct is checked against: the interface declared in the variant of its package
+// the package of strct sees, or the interface itself if it doesn't see any.
+func (v *interfaceVariants) of(strct Struct) Interface {
+	if v.iface.Named == nil || strct.Obj.Pkg() == nil {
+		return v.iface
+	}
+	pkg := v.variantSeenFrom(strct.Obj.Pkg())
+	if pkg == nil || pkg == v.iface.Named.Obj().Pkg() {
+		return v.iface
+	}
+	iface, ok := v.byPkg[pkg]
+	if !ok {
+		iface = variantInterface(v.iface, pkg)
+		v.byPkg[pkg] = iface
+	}
+	return iface
+}
+
+// variantSeenFrom returns the variant of the interface's package pkg or its imports, directly or not, refer to.
+func (v *interfaceVariants) variantSeenFrom(pkg *types.Package) *types.Package {
+	if seen, ok := v.seen[pkg]; ok {
+		return seen
+	}
+	var seen *types.Package
+	if pkg.Path() == v.iface.Named.Obj().Pkg().Path() {
+		seen = pkg
+	} else {
+		for _, imp := range pkg.Imports() {
+			if seen = v.variantSeenFrom(imp); seen != nil {
+				break
+			}
+		}
+	}
+	v.seen[pkg] = seen
+	return seen
+}
+
+// variantInterface returns iface as declared in pkg, another variant of its package. The type arguments of an
+// instantiated interface and the methods of a synthetic one, see ExportedMethodsOnly, are carried over.
+func variantInterface(iface Interface, pkg *types.Package) Interface {
+	obj, ok := pkg.Scope().Lookup(iface.Named.Obj().Name()).(*types.TypeName)
+	if !ok {
+		return iface
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return iface
+	}
+	t, ok := named.Underlying().(*types.Interface)
+	if !ok {
+		return iface
+	}
+	if len(iface.TypeArgs) > 0 {
+		instance, err := types.Instantiate(nil, named, iface.TypeArgs, false)
+		if err != nil {
+			return iface
+		}
+		t = instance.Underlying().(*types.Interface)
+	}
+	if t.NumMethods() != iface.Type.NumMethods() {
+		t = ExportedMethodsOnly(t)
+	}
+
+	variant := iface
+	variant.Named = named
+	variant.Type = t
+	if iface.Pkg.Path() == pkg.Path() {
+		variant.Pkg = pkg
+	}
+	if iface.TypeParams != nil {
+		variant.TypeParams = named.TypeParams()
+	}
+	return variant
+}
+
+// preferPlainVariant returns the package with the import path of pkg that isn't a test variant, if pkg is one and
+// pkgs or their dependencies include it, and it declares name. The packages outside of the tests refer to that one.
+// The test variant is kept for the names only its _test.go files declare.
+func preferPlainVariant(pkgs []*packages.Package, pkg *packages.Package, name string) *packages.Package {
+	if pkg.ID == pkg.PkgPath {
+		return pkg
+	}
+	plain := pkg
+	packages.Visit(pkgs, func(p *packages.Package) bool {
+		if p.PkgPath == pkg.PkgPath && p.ID == p.PkgPath && p.Types != nil && p.Types.Scope().Lookup(name) != nil {
+			plain = p
+		}
+		return plain == pkg
+	}, nil)
+	return plain
+}
@@ -0,0 +1,92 @@
+package inspector
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+)
+
+// Workspace is a go.work workspace, whose modules the go command treats as main modules.
+type Workspace struct {
+	// File is the path of the go.work file.
+	File    string
+	Modules []WorkspaceModule
+}
+
+// WorkspaceModule is a module used by a workspace.
+type WorkspaceModule struct {
+	Path string
+	Dir  string
+}
+
+// FindWorkspace returns the workspace the go command, run in dir with env, uses, or nil outside of one
+// or with GOWORK=off.
+func FindWorkspace(dir string, env []string) (*Workspace, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "env", "GOWORK")
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go env GOWORK: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	file := strings.TrimSpace(stdout.String())
+	if file == "" || file == "off" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	work, err := modfile.ParseWork(file, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	ws := &Workspace{File: file}
+	for _, use := range work.Use {
+		modDir := use.Path
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(filepath.Dir(file), modDir)
+		}
+		gomod, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+		ws.Modules = append(ws.Modules, WorkspaceModule{Path: modfile.ModulePath(gomod), Dir: modDir})
+	}
+	return ws, nil
+}
+
+// Patterns returns the package patterns matching all packages of the modules of w, like example.com/a/...,
+// or only the ones of the module with the path module if it isn't empty.
+func (w *Workspace) Patterns(module string) ([]string, error) {
+	patterns := make([]string, 0, len(w.Modules))
+	for _, m := range w.Modules {
+		if module == "" || m.Path == module {
+			patterns = append(patterns, m.Path+"/...")
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("%s doesn't use a module %q", w.File, module)
+	}
+	return patterns, nil
+}
+
+// FilterModulePackages keeps the packages of pkgs that belong to the module with the path module.
+func FilterModulePackages(pkgs []*packages.Package, module string) []*packages.Package {
+	kept := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg.Module != nil && pkg.Module.Path == module {
+			kept = append(kept, pkg)
+		}
+	}
+	return kept
+}
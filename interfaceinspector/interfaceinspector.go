@@ -0,0 +1,188 @@
+// Package interfaceinspector defines an Analyzer that checks that types implement the interfaces they are expected to.
+//
+// The rules are given with flags, both repeatable and taking comma separated values:
+//
+//	-require example.com/proj/storage=example.com/proj/storage.Repository
+//		every exported type of the package example.com/proj/storage but interfaces must implement storage.Repository
+//	-expect example.com/proj/storage.PostgresStore=example.com/proj/storage.Repository
+//		the type PostgresStore must implement storage.Repository
+//
+// A type implements an interface if either the type or a pointer to it does. The interface must be declared in the
+// analyzed package or in one of the packages it imports, directly or not.
+package interfaceinspector
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+	"golang.org/x/tools/go/analysis"
+)
+
+const doc = `check that types implement the interfaces they are expected to
+
+The -require flag takes PKG=IFACE rules, requiring every exported type of the package PKG but interfaces
+to implement the interface IFACE. The -expect flag takes TYPE=IFACE rules, requiring the type TYPE to
+implement IFACE. Types and interfaces are fully qualified, like example.com/proj/storage.Repository.`
+
+// Analyzer reports the types that don't implement the interfaces required by its flags.
+var Analyzer = &analysis.Analyzer{
+	Name: "interfaceinspector",
+	Doc:  doc,
+	Run:  run,
+}
+
+var (
+	requires rules
+	expects  rules
+)
+
+func init() {
+	Analyzer.Flags.Var(&requires, "require", "PKG=IFACE rules: every exported type of PKG must implement IFACE")
+	Analyzer.Flags.Var(&expects, "expect", "TYPE=IFACE rules: TYPE must implement IFACE")
+}
+
+// rule is a PKG=IFACE or TYPE=IFACE pair given with -require or -expect.
+type rule struct {
+	subject string
+	iface   string
+}
+
+// rules is a flag that can be repeated and also takes comma separated values.
+type rules []rule
+
+func (r *rules) String() string {
+	pairs := make([]string, 0, len(*r))
+	for _, rule := range *r {
+		pairs = append(pairs, rule.subject+"="+rule.iface)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (r *rules) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		subject, iface, ok := strings.Cut(v, "=")
+		if !ok || subject == "" {
+			return fmt.Errorf("invalid rule %q, expected something like example.com/proj/pkg=example.com/proj/pkg.Iface", v)
+		}
+		if _, _, err := inspector.ParseRef(iface); err != nil {
+			return err
+		}
+		*r = append(*r, rule{subject: subject, iface: iface})
+	}
+	return nil
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, r := range requires {
+		if r.subject != pass.Pkg.Path() {
+			continue
+		}
+		scope := pass.Pkg.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() || !obj.Exported() {
+				continue
+			}
+			if _, ok := obj.Type().Underlying().(*types.Interface); ok {
+				continue
+			}
+			check(pass, obj, r.iface)
+		}
+	}
+
+	for _, r := range expects {
+		pkgPath, name, err := inspector.ParseRef(r.subject)
+		if err != nil {
+			return nil, fmt.Errorf("-expect: %v", err)
+		}
+		if pkgPath != pass.Pkg.Path() {
+			continue
+		}
+		obj, ok := pass.Pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("-expect: no type %q in package %q", name, pkgPath)
+		}
+		check(pass, obj, r.iface)
+	}
+	return nil, nil
+}
+
+// check reports obj if it doesn't implement the interface referenced by ifaceRef.
+func check(pass *analysis.Pass, obj *types.TypeName, ifaceRef string) {
+	iface, err := findInterface(pass.Pkg, ifaceRef)
+	if err != nil {
+		pass.Reportf(obj.Pos(), "%s can't be checked against %s: %v", obj.Name(), ifaceRef, err)
+		return
+	}
+
+	strct := inspector.Struct{
+		Obj:      obj,
+		Type:     obj.Type().Underlying(),
+		Name:     obj.Name(),
+		PkgPath:  pass.Pkg.Path(),
+		Position: pass.Fset.Position(obj.Pos()),
+	}
+	e := inspector.Explain(strct, iface)
+	if e.Receiver != "" {
+		return
+	}
+
+	problems := make([]string, 0, len(e.Missing))
+	for _, m := range e.Missing {
+		if m.WrongSignature {
+			problems = append(problems, fmt.Sprintf("wrong signature for %s", m.Method.Name()))
+		} else {
+			problems = append(problems, fmt.Sprintf("missing %s", m.Method.Name()))
+		}
+	}
+	pass.Reportf(obj.Pos(), "%s doesn't implement %s: %s", obj.Name(), ifaceRef, strings.Join(problems, ", "))
+}
+
+// findInterface looks up the interface referenced by ref in pkg and in the packages it imports, directly or not.
+func findInterface(pkg *types.Package, ref string) (inspector.Interface, error) {
+	pkgPath, name, err := inspector.ParseRef(ref)
+	if err != nil {
+		return inspector.Interface{}, err
+	}
+	ifacePkg := findImport(pkg, pkgPath, make(map[*types.Package]bool))
+	if ifacePkg == nil {
+		return inspector.Interface{}, fmt.Errorf("the package %q isn't imported", pkgPath)
+	}
+
+	obj, ok := ifacePkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return inspector.Interface{}, fmt.Errorf("no such interface %q in package %q", name, pkgPath)
+	}
+	theInterface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return inspector.Interface{}, fmt.Errorf("%q in package %q is not an interface", name, pkgPath)
+	}
+	iface := inspector.Interface{Pkg: ifacePkg, Name: name, Type: theInterface}
+	if named, ok := types.Unalias(obj.Type()).(*types.Named); ok {
+		iface.Named = named
+		iface.TypeParams = named.TypeParams()
+	}
+	return iface, nil
+}
+
+// findImport returns pkg itself or the package it imports, directly or not, with the import path pkgPath.
+func findImport(pkg *types.Package, pkgPath string, seen map[*types.Package]bool) *types.Package {
+	if pkg.Path() == pkgPath {
+		return pkg
+	}
+	seen[pkg] = true
+	for _, imp := range pkg.Imports() {
+		if seen[imp] {
+			continue
+		}
+		if found := findImport(imp, pkgPath, seen); found != nil {
+			return found
+		}
+	}
+	return nil
+}
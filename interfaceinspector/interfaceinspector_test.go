@@ -0,0 +1,38 @@
+package interfaceinspector
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// setFlags resets the rules and sets the flags of the Analyzer, the rules of a flag accumulate otherwise.
+func setFlags(t *testing.T, flags map[string]string) {
+	t.Helper()
+	requires, expects = nil, nil
+	t.Cleanup(func() { requires, expects = nil, nil })
+	for name, value := range flags {
+		if err := Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	setFlags(t, map[string]string{"require": "store/mem=store.Repository"})
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "store/mem")
+}
+
+func TestExpect(t *testing.T) {
+	setFlags(t, map[string]string{"expect": "store/cache.LRU=store.Repository,store/cache.lru=store.Repository, store/cache.lru=other.Iface"})
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "store/cache")
+}
+
+func TestInvalidRule(t *testing.T) {
+	setFlags(t, nil)
+	for _, value := range []string{"store/mem", "=store.Repository", "store/mem=Repository"} {
+		if err := Analyzer.Flags.Set("require", value); err == nil {
+			t.Errorf("-require %s succeeded, want an error", value)
+		}
+	}
+}
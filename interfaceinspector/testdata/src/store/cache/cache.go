@@ -0,0 +1,7 @@
+package cache
+
+import "store"
+
+type LRU struct{ store.Repository }
+
+type lru struct{} // want `lru doesn't implement store.Repository: missing Get, missing Put` `lru can't be checked against other.Iface: the package "other" isn't imported`
@@ -0,0 +1,27 @@
+package mem
+
+import "store"
+
+var _ store.Repository = (*Memory)(nil)
+
+type Memory struct{ m map[string]string }
+
+func (m *Memory) Get(key string) (string, error) { return m.m[key], nil }
+func (m *Memory) Put(key, value string) error    { m.m[key] = value; return nil }
+
+type ReadOnly struct{} // want `ReadOnly doesn't implement store.Repository: missing Put`
+
+func (ReadOnly) Get(key string) (string, error) { return "", nil }
+
+type Counter int // want `Counter doesn't implement store.Repository: wrong signature for Get, missing Put`
+
+func (Counter) Get(key string) int { return 0 }
+
+// interfaces, aliases and unexported types aren't required to implement it
+type Getter interface {
+	Get(key string) (string, error)
+}
+
+type Alias = ReadOnly
+
+type entry struct{}
@@ -0,0 +1,6 @@
+package store
+
+type Repository interface {
+	Get(key string) (string, error)
+	Put(key, value string) error
+}
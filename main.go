@@ -1,40 +1,219 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/token"
 	"go/types"
 	"os"
+	"os/signal"
+	"regexp"
+	"runtime"
 	"strings"
+	"syscall"
+	"text/template"
 
 	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
 )
 
-type findInterfaceResult struct {
-	pkg       types.Package
-	iface     *types.Interface
-	ifaceName string
-}
+// exitFailed is the exit code of a query that ran fine but whose result is a failure, like an interface without
+// implementers or an assertion that doesn't hold, so that scripts can tell it apart from errors, which exit with 1.
+const exitFailed = 2
 
-type strctFound struct {
-	obj      types.Object
-	strct    types.Struct
-	name     string
-	position token.Position
-}
-
-func (s *strctFound) String() string {
-	return fmt.Sprintf("%s %s:%d:%d", s.name, s.position.Filename, s.position.Line, s.position.Column)
-}
+// exitInterrupted is the exit code of an interrupted run, like the one of a shell.
+const exitInterrupted = 130
 
 const Usage = `Usage: interface-inspector [OPTIONS]
 
 Options:
  package_dir	The directory that contains the package where the interface is defined
  package	The name of the package that the interface belongs to
- interface	The name of the interface
+ interface	The name of the interface, or a fully qualified interface like -iface. A glob like '*' or '*Store'
+		lists the implementers of every matching exported interface of the package, grouped by interface.
+		Without -interface, the implementers of all the exported interfaces of -package are listed
+ iface		The fully qualified interface, e.g. github.com/me/proj/pkg/cmd.Stringer or io.Reader. Replaces -package,
+		-package_dir and -interface and matches the package on its exact import path. The package is loaded
+		even if the scanned packages don't depend on it, so interfaces of the standard library and of any
+		module in go.mod can be used. Its own types are only scanned if it belongs to the current module
+ struct		Instead of the implementers of -interface, list the interfaces declared in the scanned packages that
+		this type implements. Either a name in -package or a qualified reference like pkg/db.Store,
+		whose import path may be shortened to its last elements. Mutually exclusive with -interface
+ stdlib		With -struct, also check the interfaces of the standard library packages the scanned packages depend on
+ type-args	The comma separated type arguments to instantiate a generic interface with, e.g. "string, int".
+		They may refer to predeclared types and to the types of the interface's package.
+		Without them, generic types are checked against the generic interface using their own type parameters
+ instantiations	Check the instantiations of a generic interface used in the scanned packages, like Cache[string, int],
+		and report the implementers of each one. Generic types are checked through their instantiations
+		used in the scanned packages, like lru[string, int]
+ search_path	The packages to load and scan for structs, in the package pattern syntax of the go command.
+		Comma separated or repeated. Defaults to ./... The patterns may also be given as arguments.
+		The interface's package is looked up among them first and then among their dependencies
+ scan		Same as search_path
+ exclude	Don't scan the packages whose import path or directory, relative to the current directory,
+		matches one of these comma separated or repeated globs, like */mocks or internal/gen/...
+		A glob ending in /... also matches everything below
+ module		Only scan the packages of the module with this path. In a go.work workspace, the packages of all its modules
+		are scanned by default, and this one restricts the scan to one of them
+ dir		Run as if the program was started in this directory, e.g. the root of the module
+ lang		Type check the code as if the module targeted this Go language version, e.g. go1.21.
+		Code that doesn't compile under that version is reported. Needs a go.mod in the current directory
+ receiver	Only report the implementers whose value satisfies the interface (value), the ones that
+		only satisfy it through a pointer (pointer) or both (any, the default)
+ only-stubs	Only report implementers whose methods are all stubs: empty, a single panic(...) or a single return of zero values
+ exclude-stubs	Don't report implementers whose methods are all stubs
+ allowlist	A file listing the qualified names of the types approved to implement the interface, one per line.
+		Fails with the unapproved implementers if there are any
+ update-allowlist	Write the current implementers to the -allowlist file instead of checking them
+ env		Print the Go version, module, build tags, target platform and number of loaded packages and exit.
+		Doesn't need -package and -interface. Supports -format json
+ self-test	Check the inspector against embedded fixtures with known implementers and exit
+ include-unexported-methods	Consider the unexported methods of the interface. Defaults to true, which matches the Go spec.
+		With -include-unexported-methods=false only the exported methods are matched, for implementers and near misses alike
+ format		The output format: text (default), json, go-slice, a Go slice literal of the implementers,
+		or plantuml, a class diagram of the interface and its implementers,
+		or dot, a Graphviz graph with an edge from every implementer to the interface,
+		or grep, file:line:col: message lines like grep -n, which Vim reads with :cexpr,
+		or quickfix, the same with an info or warning severity for editor problem matchers.
+		With grep and quickfix, the near misses of -near-miss are listed with what they lack.
+		sarif writes a SARIF log for GitHub code scanning with the findings: no implementers, near misses
+		(always included), implementers missing from the -allowlist, assertions of -assert that don't hold
+		and the interfaces of -dead-interfaces.
+		A format containing {{ is a Go text/template executed for every implementer, followed by a newline
+		unless it ends with one, like -format '{{.Package}}.{{.Name}} {{.Position}}'. The fields are
+		Name, Package (import path), PackageName, Kind, Receiver (value or pointer), Position (file:line:column),
+		Filename, Line, Column, Interface (qualified name) and Methods, the methods of the interface,
+		each with a Name and a Signature
+		json prints an array of objects with the name, package_path, filename, line, column, type, kind, receiver and
+		implemented interface of every implementer, also for -struct. No implementers result in an empty array
+ plantuml-methods	List the methods of the interface in the plantuml diagram
+ dot-embedded	Also draw the interfaces embedded by the interface, directly or not, in the dot graph
+ interfaces-used-as-params	List the named interfaces used as function parameters in the scanned packages, most used first.
+		Doesn't need -package and -interface
+ serve		Keep the packages loaded and answer queries over HTTP on this address, like localhost:8080.
+		The packages are loaded again when a file changes. The answers are JSON, like with -format json:
+		GET /implementers?interface=REF, GET /interfaces?type=REF (the interfaces a type implements),
+		GET /near-misses?interface=REF and GET /explain?type=REF&interface=REF (like -why).
+		The references are qualified, like pkg/db.Store. Doesn't need -package and -interface
+ repl		Load the packages once and read commands from stdin, one per line, at a prompt: search the interfaces,
+		show their implementers and near misses, and the interfaces an implementer implements. Type ? for the
+		commands. Doesn't need -package and -interface
+ dead-interfaces	List the interfaces of the scanned packages that no scanned type implements or that are never
+		used, as a parameter, result, field, variable, in a type assertion or otherwise. Compile time
+		checks like var _ Store = (*postgresStore)(nil) don't count as uses. Doesn't need -package and -interface
+ compare		Compare the interface with this fully qualified one, like github.com/me/proj/b.Source: whether its
+		methods are a subset or superset of the other's, the methods they have in common, only one of them has
+		or both have with different signatures, and the scanned types implementing both
+ duplicate-interfaces	List the groups of interfaces of the scanned packages with identical method sets, which are
+		likely duplicate abstractions. Doesn't need -package and -interface
+ usages		After the implementers, print every place the scanned packages use the interface at: parameters,
+		results, struct fields, variables, type assertions, type switches, embeddings and other uses
+ near-miss	After the implementers, print the structs that have some but not all methods of the interface,
+		with the methods they are missing or have with a wrong signature. Use -near-miss-json for JSON
+ generate-stubs	With -struct, print a method with a panic("unimplemented") body for every method of the interface
+		the type lacks, with the receiver name and kind of its other methods. Methods it has with a wrong
+		signature are listed in a comment since they need to be fixed by hand
+ w		With -generate-stubs, append the stubs to the file declaring the type instead of printing them,
+		and add the imports they need
+ why		Explain why the type with this name, or qualified name, does or doesn't implement the interface:
+		list the methods it is missing, has with a wrong signature or only has on its pointer
+ near-miss-json	Print the structs that implement some but not all methods of the interface as JSON
+ methods	Print the methods of the interface, including the ones of embedded interfaces, with their documentation
+ embedding-tree	Print the interface with the interfaces it embeds, directly or not, as a tree: every interface with the
+		methods it declares itself and whether it comes from the main module, the standard library or a third-party module
+ embedders	List the interfaces of the scanned packages that embed the interface, directly or through the interfaces listed
+		after via
+ cost		Print the number of methods needed to implement the interface and the distinct types in their signatures
+ group-by-embedded	Group the implementers by the embedded types that provide the methods satisfying the interface
+ registrations	Instead of the implementers, print the values implementing the interface that are passed to a registration
+		function in an init function or a package level variable declaration, with the call site
+ register-func	Comma separated names of the registration functions used by -registrations. Defaults to "Register,register"
+ allow-empty	List the implementers of an interface without methods, which are all the scanned types
+ count		Only print the number of implementers, and of near misses with -near-miss. Fails if there are no implementers
+ sort		Order the implementers by position (file, line, column), file (only the file, ties keep their order), name, or package (import path,
+		then position)
+ exported-only	Only report exported types, as implementers and near misses
+ pkg-filter	Only report the types of the packages whose import path matches this regular expression, like 'internal/.*'
+ name-regex	Only report the types whose name matches this regular expression, like '^Mock'
+ exclude-name-regex	Don't report the types whose name matches this regular expression, like '^(Mock|Fake)'
+ limit		Only print the first N implementers, in the -sort order if given
+ v		Verbose output. Show the receiver of every method a struct uses to satisfy the interface.
+		Every implementer is annotated with "(value receiver)" if the struct value satisfies the interface
+		or with "(pointer receiver)" if only a pointer to it does
+ show-methods	Under every implementer, list the methods satisfying the interface with their receivers and positions,
+		and whether they are declared by the type or promoted, with the chain of embedded fields they come through
+ check-assignable	Also check every struct with types.AssignableTo and report where it disagrees with types.Implements
+ struct-scope	Only load and scan the structs of this one package, given as a directory or an import path.
+		The interface's package is loaded too. Much faster than loading the whole module on big repositories
+ package-conformance	Check every exported type of the package with this import path against the interface and print
+		whether it conforms, is a near miss or is unrelated
+ direct-children	Only scan the structs of the packages that are immediate children of the given import path
+ sizes		Report the size and field count of each struct as laid out by the given compiler (gc or gccgo)
+ tags		Comma separated build tags to load the packages with, like the -tags flag of the go command
+ goos		Load the packages for this operating system instead of the current one, e.g. windows
+ goarch		Load the packages for this architecture instead of the current one. Also used to compute -sizes.
+		Defaults to the architecture of the running program
+ base		Compare the implementers with the ones of this git revision, like origin/main, checked out in a temporary
+		git worktree. Prints the added implementers prefixed with +, the removed ones with -, the ones implementing
+		the interface with another receiver with ~, and the broken ones, whose type still exists but doesn't
+		implement the interface anymore, with ! and what they lack. Fails if an implementation broke
+ all-platforms	Load the packages once for every platform of -platforms and merge the implementers, annotated with
+		the platforms they implement the interface on, or all. Implementers declared in files for other platforms,
+		like file_linux.go and file_windows.go, are listed apart
+ platforms	Comma separated or repeated GOOS/GOARCH pairs checked by -all-platforms.
+		Defaults to linux/amd64, linux/arm64, darwin/amd64, darwin/arm64 and windows/amd64
+ width		Truncate every result line so that it fits in the given number of columns, shortening what
+		follows the name, like the receiver kind and the details, with an ellipsis. The name and the position are
+		kept whole, even if they don't fit. Defaults to the terminal width when printing to a terminal
+ no-truncate	Never truncate result lines
+ include-aliases	Also report type aliases, like type Store = postgresStore, next to the types they stand for
+ include-local	Also report the types declared inside functions, like the fakes of tests, named after their function
+		like TestFetch.fakeFetcher. They can't have methods, so they only implement interfaces through embedded fields
+ tests		Whether the types declared in _test.go files, including external test packages like foo_test,
+		are scanned: exclude (the default), include, or only, which only reports them, like test fakes
+ include-tests	Same as -tests include
+ include-vendor	Also scan vendored packages
+ deps		Also scan the packages of the modules the scanned packages depend on, directly or not, to find
+		third-party implementers. The standard library isn't scanned
+ deps-module	With -deps, only scan the modules whose path starts with one of these comma separated or repeated prefixes
+ no-gitignore	Also scan directories that are ignored by the .gitignore file at the root of the git repository
+ export-data	Load the types from the export data of the go build cache instead of type checking the source.
+		The cache is kept across runs and only the changed packages are compiled again, which makes
+		repeated queries on big modules much faster. Can't be used with the options that need the source:
+		-only-stubs, -exclude-stubs, -registrations, -interfaces-used-as-params, -instantiations,
+		-dead-interfaces, -usages and -include-local
+ jobs		The number of goroutines scanning the packages for structs and checking them against the interface.
+		Defaults to GOMAXPROCS
+ watch		Run the query again every time a .go file under the current directory is added, removed or changed.
+		The packages stay loaded and only the changed ones, and the ones importing them, are type checked
+		again. Can't be used with -run, -batch, -repl, -serve, -base, -all-platforms or -self-test
+ run		Run the named queries of the -config file in one go and print the implementers of each under its name,
+		followed by ok or FAIL. A query fails without implementers or, if it lists the expected implementers,
+		with other ones. The names and ok or FAIL of the queries with another format than text are printed to
+		stderr. The packages of all queries are loaded once. Replaces -interface
+ batch		Read one query per line from stdin, a fully qualified interface optionally followed by package patterns,
+		like "github.com/me/proj/storage.Store ./internal/...", and print the result of every query when it's done,
+		like -run. The packages of -scan are loaded once for all queries, so the patterns of a query only narrow
+		them down and its interface has to be loaded with them. Supports -format json, grep and quickfix, which
+		print the queries and ok or FAIL to stderr. -format json prints a line of JSON per query, like
+		{"query": "...", "implementers": [...]}. Replaces -interface
+ config		The YAML file of the queries of -run. Defaults to .interface-inspector.yaml:
+		  queries:
+		    - name: stores
+		      interface: github.com/me/proj/storage.Store
+		      scan: [./internal/...]           # package patterns, like -scan. Defaults to ./...
+		      exclude: [internal/mocks/...]    # like -exclude
+		      implementers: [github.com/me/proj/internal/postgres.Store]
+		      format: text                     # or json, grep or quickfix
+ assert		Check that a type implements an interface, like -assert "pkg/db.PostgresStore implements pkg/db.Store",
+		and print the methods it lacks or has with a wrong signature if it doesn't. Repeatable.
+		The import paths may be shortened to their trailing elements. Replaces -interface
+
+Exit status:
+ 0 on success, 1 on errors like an invalid flag or a package that fails to load, 2 if the result is a failure:
+ no implementers, an unapproved implementer, an assertion or -run query that doesn't hold, a broken
+ implementation with -base or a failed self-test
 
 Example:
  interface-inspector -package_dir pkg/cmd -package cmd -interface Stringer	This will show all structs implementing the interface "Stringer".
@@ -42,113 +221,1137 @@ Example:
 										The structs to be examined are all under path "pkg"`
 
 func main() {
+	os.Exit(realMain())
+}
+
+// realMain runs the command and returns its exit status, so that main only exits once the deferred calls ran.
+func realMain() int {
 	packageDirectory := flag.String("package_dir", ".", "path of the package containing the interface")
 	packageName := flag.String("package", "", "the package name")
 	interfaceName := flag.String("interface", "", "the name of the interface")
+	ifaceRef := flag.String("iface", "", "the fully qualified interface, e.g. github.com/me/proj/pkg/cmd.Stringer")
+	structName := flag.String("struct", "", "list the interfaces this type implements")
+	stdlib := flag.Bool("stdlib", false, "with -struct, also check the interfaces of the standard library")
+	typeArgs := flag.String("type-args", "", "the type arguments to instantiate a generic interface with")
+	instantiations := flag.Bool("instantiations", false, "report the implementers of the instantiations used in the code")
+	var searchPaths listFlag
+	flag.Var(&searchPaths, "search_path", "package patterns to scan, comma separated or repeated (default ./...)")
+	flag.Var(&searchPaths, "scan", "same as -search_path")
+	deps := flag.Bool("deps", false, "also scan the packages of the module dependencies")
+	var depsModules listFlag
+	flag.Var(&depsModules, "deps-module", "with -deps, only scan the modules with these path prefixes")
+	var excludes listFlag
+	flag.Var(&excludes, "exclude", "globs of the import paths or directories of the packages not to scan")
+	module := flag.String("module", "", "only scan the packages of this module, like a module of the go.work workspace")
+	dir := flag.String("dir", "", "the directory to run in, e.g. the root of the module")
+	lang := flag.String("lang", "", "the Go language version to type check the code with, e.g. go1.21")
+	receiver := flag.String("receiver", "any", "only report implementers satisfying the interface by value, pointer or any")
+	onlyStubs := flag.Bool("only-stubs", false, "only report implementers whose methods are all stubs")
+	excludeStubs := flag.Bool("exclude-stubs", false, "don't report implementers whose methods are all stubs")
+	allowlist := flag.String("allowlist", "", "file with the types approved to implement the interface")
+	updateAllowlist := flag.Bool("update-allowlist", false, "regenerate the -allowlist file from the current implementers")
+	printEnvironment := flag.Bool("env", false, "print information about the scan environment and exit")
+	selfTest := flag.Bool("self-test", false, "check the inspector against embedded fixtures and exit")
+	includeUnexported := flag.Bool("include-unexported-methods", true, "consider the unexported methods of the interface")
+	format := flag.String("format", "text", "output format: text, json, go-slice, plantuml, dot, grep, quickfix or sarif")
+	dotEmbedded := flag.Bool("dot-embedded", false, "draw the embedded interfaces in the dot graph")
+	plantumlMethods := flag.Bool("plantuml-methods", false, "list the interface methods in the plantuml diagram")
+	serveAddr := flag.String("serve", "", "answer queries over HTTP on this address, like localhost:8080")
+	replMode := flag.Bool("repl", false, "read commands searching the interfaces and their implementers from stdin")
+	listDeadInterfaces := flag.Bool("dead-interfaces", false, "list the interfaces without implementers or usages")
+	listParamInterfaces := flag.Bool("interfaces-used-as-params", false, "list the interfaces used as function parameters")
+	compareRef := flag.String("compare", "", "compare the interface with this qualified interface")
+	listDuplicateInterfaces := flag.Bool("duplicate-interfaces", false, "list the interfaces with identical method sets")
+	showUsages := flag.Bool("usages", false, "also print where the interface is used")
+	showNearMisses := flag.Bool("near-miss", false, "also print the structs that almost implement the interface")
+	generateStubs := flag.Bool("generate-stubs", false, "with -struct, print stubs of the methods the type lacks to implement the interface")
+	writeFile := flag.Bool("w", false, "with -generate-stubs, append the stubs to the file of the type")
+	why := flag.String("why", "", "explain why this type does or doesn't implement the interface")
+	printNearMissJSON := flag.Bool("near-miss-json", false, "print the structs that almost implement the interface as JSON")
+	describeMethods := flag.Bool("methods", false, "print the methods of the interface and exit")
+	embeddingTree := flag.Bool("embedding-tree", false, "print the interfaces the interface embeds as a tree and exit")
+	listEmbedders := flag.Bool("embedders", false, "list the interfaces embedding the interface")
+	showCost := flag.Bool("cost", false, "print how many methods and types implementing the interface involves and exit")
+	groupByEmbedded := flag.Bool("group-by-embedded", false, "group implementers by the embedded types providing their methods")
+	listRegistrations := flag.Bool("registrations", false, "print the implementers passed to registration functions")
+	registerFuncs := flag.String("register-func", "Register,register", "comma separated names of the registration functions")
+	allowEmpty := flag.Bool("allow-empty", false, "list the implementers of an interface without methods")
+	count := flag.Bool("count", false, "only print the number of implementers")
+	sortBy := flag.String("sort", "", "order the implementers by position, file, name or package")
+	exportedOnly := flag.Bool("exported-only", false, "only report exported types")
+	pkgFilter := flag.String("pkg-filter", "", "only report the types of the packages whose import path matches this regexp")
+	nameRegex := flag.String("name-regex", "", "only report the types whose name matches this regexp")
+	excludeNameRegex := flag.String("exclude-name-regex", "", "don't report the types whose name matches this regexp")
+	limit := flag.Int("limit", 0, "only print the first N implementers")
+	verbose := flag.Bool("v", false, "verbose output")
+	showMethods := flag.Bool("show-methods", false, "list the methods every implementer satisfies the interface with")
+	checkAssignable := flag.Bool("check-assignable", false, "report structs for which assignability and implementation disagree")
+	structScope := flag.String("struct-scope", "", "only load and scan the structs of this package (directory or import path)")
+	packageConformance := flag.String("package-conformance", "", "check every exported type of this package against the interface")
+	directChildren := flag.String("direct-children", "", "only scan the packages directly under this import path")
+	sizesCompiler := flag.String("sizes", "", "report struct sizes as computed by the given compiler (gc or gccgo)")
+	buildTags := flag.String("tags", "", "comma separated build tags to load the packages with")
+	goos := flag.String("goos", "", "the operating system to load the packages for")
+	goarch := flag.String("goarch", "", "the architecture to load the packages for and to compute struct sizes with")
+	baseRev := flag.String("base", "", "compare the implementers with the ones of this git revision")
+	allPlatforms := flag.Bool("all-platforms", false, "check every platform of -platforms and merge the implementers")
+	var platformList listFlag
+	flag.Var(&platformList, "platforms", "the GOOS/GOARCH pairs checked by -all-platforms, comma separated or repeated")
+	width := flag.Int("width", 0, "truncate result lines to this many columns (default: terminal width)")
+	noTruncate := flag.Bool("no-truncate", false, "never truncate result lines")
+	includeAliases := flag.Bool("include-aliases", false, "also report type aliases")
+	includeLocal := flag.Bool("include-local", false, "also report the types declared inside functions")
+	tests := flag.String("tests", "exclude", "scan the types of the _test.go files: exclude, include or only")
+	includeTests := flag.Bool("include-tests", false, "same as -tests include")
+	includeVendor := flag.Bool("include-vendor", false, "also scan vendored packages")
+	noGitignore := flag.Bool("no-gitignore", false, "don't skip directories ignored by .gitignore")
+	watchMode := flag.Bool("watch", false, "run the query again every time a .go file changes")
+	var assertions repeatedFlag
+	flag.Var(&assertions, "assert", `assert that a type implements an interface, like "pkg/db.PostgresStore implements pkg/db.Store"`)
+	runConfig := flag.Bool("run", false, "run the queries of the -config file")
+	batchMode := flag.Bool("batch", false, "answer the queries read from stdin, one per line")
+	configPath := flag.String("config", defaultConfig, "the YAML file of the queries of -run")
+	exportData := flag.Bool("export-data", false, "load the types from the export data of the go build cache")
+	jobs := flag.Int("jobs", runtime.GOMAXPROCS(0), "the number of goroutines scanning packages and checking types")
 
 	flag.Usage = func() {
 		fmt.Println(Usage)
 	}
 	flag.Parse()
 
-	if *interfaceName == "" || *packageName == "" {
+	if *watchMode && (*runConfig || *batchMode || *replMode || *serveAddr != "" || *baseRev != "" || *allPlatforms || *selfTest) {
+		fmt.Println("error: -watch can't be used with -run, -batch, -repl, -serve, -base, -all-platforms or -self-test")
+		return 1
+	}
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			fmt.Printf("error: -dir: %v\n", err)
+			return 1
+		}
+	}
+
+	if *selfTest {
+		passed, err := runSelfTest(os.Stdout)
+		if err != nil {
+			fmt.Printf("error: self-test: %v\n", err)
+			return 1
+		}
+		if !passed {
+			return exitFailed
+		}
+		return 0
+	}
+
+	if *onlyStubs && *excludeStubs {
+		fmt.Println("error: -only-stubs and -exclude-stubs are mutually exclusive")
+		return 1
+	}
+
+	if *exportData && (*onlyStubs || *excludeStubs || *listRegistrations || *listParamInterfaces || *instantiations || *listDeadInterfaces || *showUsages || *includeLocal) {
+		fmt.Println("error: -export-data doesn't load the source, which -only-stubs, -exclude-stubs, -registrations, -interfaces-used-as-params, -instantiations, -dead-interfaces, -usages and -include-local need")
+		return 1
+	}
+
+	scanOpts := scanOptions{
+		jobs:      *jobs,
+		testsOnly: *tests == "only",
+		aliases:   *includeAliases,
+		locals:    *includeLocal,
+		filter:    inspector.StructFilter{ExportedOnly: *exportedOnly},
+	}
+	for _, re := range []struct {
+		flag, expr string
+		dst        **regexp.Regexp
+	}{
+		{"pkg-filter", *pkgFilter, &scanOpts.filter.Package},
+		{"name-regex", *nameRegex, &scanOpts.filter.Name},
+		{"exclude-name-regex", *excludeNameRegex, &scanOpts.filter.ExcludeName},
+	} {
+		if re.expr == "" {
+			continue
+		}
+		compiled, err := regexp.Compile(re.expr)
+		if err != nil {
+			fmt.Printf("error: -%s: %v\n", re.flag, err)
+			return 1
+		}
+		*re.dst = compiled
+	}
+
+	platforms := inspector.DefaultPlatforms
+	if len(platformList) > 0 {
+		platforms = make([]inspector.Platform, 0, len(platformList))
+		for _, s := range platformList {
+			p, err := inspector.ParsePlatform(s)
+			if err != nil {
+				fmt.Printf("error: -platforms: %v\n", err)
+				return 1
+			}
+			platforms = append(platforms, p)
+		}
+	}
+	if *allPlatforms && (*goos != "" || *goarch != "") {
+		fmt.Println("error: -all-platforms and -goos or -goarch are mutually exclusive")
+		return 1
+	}
+	if len(platformList) > 0 && !*allPlatforms {
+		fmt.Println("error: -platforms needs -all-platforms")
+		return 1
+	}
+
+	if *updateAllowlist && *allowlist == "" {
+		fmt.Println("error: -update-allowlist needs -allowlist")
+		return 1
+	}
+
+	if *receiver != "any" && *receiver != string(inspector.ValueReceiver) && *receiver != string(inspector.PointerReceiver) {
+		fmt.Printf("error: unknown receiver %q, expected value, pointer or any\n", *receiver)
+		return 1
+	}
+
+	if *includeTests {
+		*tests = "include"
+	}
+	if *tests != "exclude" && *tests != "include" && *tests != "only" {
+		fmt.Printf("error: unknown -tests %q, expected exclude, include or only\n", *tests)
+		return 1
+	}
+
+	if *format != "text" && *format != "go-slice" && *format != "plantuml" && *format != "json" && *format != "dot" &&
+		*format != "grep" && *format != "quickfix" && *format != "sarif" && !isTemplate(*format) {
+		fmt.Printf("error: unknown format %q\n", *format)
+		return 1
+	}
+	var tmpl *template.Template
+	if isTemplate(*format) {
+		var err error
+		tmpl, err = parseTemplate(*format)
+		if err != nil {
+			fmt.Printf("error: -format: %v\n", err)
+			return 1
+		}
+	}
+
+	// -interface io.Reader is a shorthand for -iface io.Reader
+	if strings.Contains(*interfaceName, ".") && *ifaceRef == "" {
+		*ifaceRef, *interfaceName = *interfaceName, ""
+	}
+
+	if *generateStubs && (*structName == "" || (*interfaceName == "" && *ifaceRef == "")) {
+		fmt.Println("error: -generate-stubs needs -struct and -interface")
+		return 1
+	}
+	if *writeFile && !*generateStubs {
+		fmt.Println("error: -w needs -generate-stubs")
+		return 1
+	}
+
+	if *structName != "" && !*generateStubs && (*interfaceName != "" || *ifaceRef != "") {
+		fmt.Println("error: -struct and -interface are mutually exclusive")
+		return 1
+	}
+
+	parsedAssertions := make([]inspector.Assertion, 0, len(assertions))
+	for _, a := range assertions {
+		parsed, err := inspector.ParseAssertion(a)
+		if err != nil {
+			fmt.Printf("error: -assert: %v\n", err)
+			return 1
+		}
+		parsedAssertions = append(parsedAssertions, parsed)
+	}
+	if (*runConfig || *batchMode) && (len(parsedAssertions) > 0 || *interfaceName != "" || *ifaceRef != "" || *structName != "") {
+		fmt.Println("error: -run or -batch and -interface, -struct or -assert are mutually exclusive")
+		return 1
+	}
+	if len(parsedAssertions) > 0 && (*interfaceName != "" || *ifaceRef != "" || *structName != "") {
+		fmt.Println("error: -assert and -interface or -struct are mutually exclusive")
+		return 1
+	}
+
+	// otherMode is set if one of the modes that don't look up interfaces or types by name takes over the run
+	otherMode := len(parsedAssertions) > 0 || *runConfig || *batchMode || *listParamInterfaces || *listDeadInterfaces ||
+		*listDuplicateInterfaces || *replMode || *serveAddr != "" || *printEnvironment
+
+	if !otherMode && *ifaceRef == "" && (*structName == "" || !strings.Contains(*structName, ".")) && *packageName == "" {
 		flag.Usage()
-		os.Exit(1)
+		return 1
 	}
 
-	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax}, "./...")
+	// -interface '*', or -package without -interface, lists the implementers of every matching exported interface
+	var interfaceGlob string
+	if !otherMode && (*structName == "" || *generateStubs) {
+		if *ifaceRef != "" {
+			if _, name, err := inspector.ParseRef(*ifaceRef); err == nil && isGlob(name) {
+				interfaceGlob = name
+			}
+		} else if *interfaceName == "" {
+			interfaceGlob = "*"
+		} else if isGlob(*interfaceName) {
+			interfaceGlob = *interfaceName
+		}
+	}
+	if interfaceGlob != "" && *format != "text" && *format != "json" && *format != "dot" && *format != "grep" && *format != "quickfix" && tmpl == nil {
+		fmt.Printf("error: -format %s lists the implementers of a single interface\n", *format)
+		return 1
+	}
+
+	query := inspector.Query{Interface: *ifaceRef, Tests: *tests != "exclude", Jobs: *jobs, ExportData: *exportData}
+	if *buildTags != "" {
+		query.BuildFlags = append(query.BuildFlags, "-tags="+*buildTags)
+	}
+	if *goos != "" || *goarch != "" {
+		query.Env = os.Environ()
+		if *goos != "" {
+			query.Env = append(query.Env, "GOOS="+*goos)
+		}
+		if *goarch != "" {
+			query.Env = append(query.Env, "GOARCH="+*goarch)
+		}
+	}
+	if *lang != "" {
+		modfile, tmpDir, err := inspector.LangModfile(".", *lang)
+		if err != nil {
+			fmt.Printf("error: -lang: %v\n", err)
+			return 1
+		}
+		defer os.RemoveAll(tmpDir)
+		// -watch and -serve only stop on an interrupt
+		removeOnSignal(tmpDir)
+		query.BuildFlags = append(query.BuildFlags, "-modfile="+modfile)
+	}
+
+	patterns := append([]string(searchPaths), flag.Args()...)
+	// in a go.work workspace, the packages of all its modules are scanned, wherever they are
+	ws, err := inspector.FindWorkspace(".", query.Env)
 	if err != nil {
-		fmt.Printf("error: load packages: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("error: %v\n", err)
+		return 1
+	}
+	if len(patterns) == 0 && ws != nil {
+		patterns, err = ws.Patterns(*module)
+		if err != nil {
+			fmt.Printf("error: -module: %v\n", err)
+			return 1
+		}
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	scopePattern, scopeIsDir := inspector.StructScopePattern(*structScope)
+	if *structScope != "" {
+		patterns = []string{scopePattern}
+		if *ifaceRef == "" {
+			patterns = append(patterns, inspector.InterfacePackagePattern(*packageDirectory))
+		}
+	}
+	// an interface referenced by its import path is loaded even if none of the scanned packages depends on it
+	var ifacePkgPath string
+	if *ifaceRef != "" {
+		path, _, err := inspector.ParseRef(*ifaceRef)
+		if err != nil {
+			fmt.Printf("error: -iface: %v\n", err)
+			return 1
+		}
+		ifacePkgPath = path
+	}
+	if *packageConformance != "" {
+		patterns = append(patterns, *packageConformance)
+	}
+	// like the interface, the one it's compared with is loaded even if no scanned package depends on it
+	if *compareRef != "" {
+		path, _, err := inspector.ParseRef(*compareRef)
+		if err != nil {
+			fmt.Printf("error: -compare: %v\n", err)
+			return 1
+		}
+		patterns = append(patterns, path)
 	}
 
-	// search for the interface in the package
-	iface, err := findInterface(pkgs, *packageName, *packageDirectory, *interfaceName)
+	query.Patterns = patterns
+
+	if _, err := inspector.FilterExcludedPackages(nil, ".", excludes); err != nil {
+		fmt.Printf("error: -exclude: %v\n", err)
+		return 1
+	}
+	filter := packageFilter{
+		includeVendor: *includeVendor,
+		deps:          *deps,
+		depsModules:   depsModules,
+		excludes:      excludes,
+		gitignore:     !*noGitignore,
+	}
+	scanPackages, err := filter.scanPackages(".")
 	if err != nil {
-		fmt.Printf("error: find interfaces: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("error: read .gitignore: %v\n", err)
+		return 1
 	}
 
-	// find structs
-	strcts := findStrcts(pkgs)
-	strctsImplementingIface := getStrctsImplementingIface(*packageDirectory, strcts, iface)
-	if len(strctsImplementingIface) == 0 {
-		fmt.Printf("error: no structs implement the interface %q defined in package %q\n", *interfaceName, *packageName)
-		os.Exit(1)
+	if *runConfig {
+		cfg, err := readConfig(*configPath)
+		if err != nil {
+			fmt.Printf("error: -config: %v\n", err)
+			return 1
+		}
+		structs := func(pkgs []*packages.Package) []inspector.Struct {
+			return scanStructs(scanPackages(pkgs), scanOpts)
+		}
+		passed, err := runQueries(os.Stdout, os.Stderr, cfg, query, structs)
+		if err != nil {
+			fmt.Printf("error: -run: %v\n", err)
+			return 1
+		}
+		if !passed {
+			return exitFailed
+		}
+		return 0
+	}
+
+	// queryImplementers finds the interface and its implementers in pkgs, among the packages scan selects, for
+	// the modes loading the packages several times
+	queryImplementers := func(pkgs []*packages.Package, scan func([]*packages.Package) []*packages.Package) (inspector.Interface, []inspector.Struct, []inspector.Implementer, error) {
+		var iface inspector.Interface
+		var err error
+		scanPkgs := pkgs
+		if *ifaceRef != "" {
+			iface, err = inspector.FindInterfaceByRef(pkgs, *ifaceRef)
+			scanPkgs = inspector.DropExternalPackage(scanPkgs, ifacePkgPath)
+		} else {
+			iface, err = inspector.FindInterface(pkgs, *packageName, *packageDirectory, *interfaceName)
+		}
+		if err != nil {
+			return inspector.Interface{}, nil, nil, err
+		}
+		if !*includeUnexported {
+			iface.Type = inspector.ExportedMethodsOnly(iface.Type)
+		}
+		strcts := scanStructs(scan(scanPkgs), scanOpts)
+		impls := inspector.ParallelImplementers(strcts, iface, *jobs)
+		if *receiver != "any" {
+			impls = filterReceiver(impls, inspector.ReceiverKind(*receiver))
+		}
+		return iface, strcts, impls, nil
+	}
+	if (*allPlatforms || *baseRev != "") && (interfaceGlob != "" || *structName != "" || len(parsedAssertions) > 0 || *format != "text") {
+		fmt.Println("error: -all-platforms and -base list the implementers of a single interface in the text format")
+		return 1
 	}
 
-	for _, strct := range strctsImplementingIface {
-		fmt.Printf("%s\n", strct.String())
+	if *baseRev != "" {
+		pkgs, err := inspector.Load(context.Background(), query)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			return 1
+		}
+		iface, strcts, impls, err := queryImplementers(pkgs, scanPackages)
+		if err != nil {
+			fmt.Printf("error: find implementers: %v\n", err)
+			return 1
+		}
+
+		baseDir, remove, err := checkoutRevision(*baseRev)
+		if err != nil {
+			fmt.Printf("error: -base: %v\n", err)
+			return 1
+		}
+		baseQuery := query
+		baseQuery.Dir = baseDir
+		basePkgs, err := inspector.Load(context.Background(), baseQuery)
+		// the excluded and ignored directories are those of the base revision
+		var baseScan func([]*packages.Package) []*packages.Package
+		if err == nil {
+			baseScan, err = filter.scanPackages(baseDir)
+		}
+		var baseImpls []inspector.Implementer
+		if err == nil {
+			_, _, baseImpls, err = queryImplementers(basePkgs, baseScan)
+		}
+		remove()
+		if err != nil {
+			fmt.Printf("error: -base %s: %v\n", *baseRev, err)
+			return 1
+		}
+
+		diff := inspector.DiffImplementers(baseImpls, impls, strcts, iface)
+		printDiff(os.Stdout, diff, iface, *baseRev, baseDir)
+		if len(diff.Broken) > 0 {
+			return exitFailed
+		}
+		return 0
 	}
-}
 
-// findInterface finds an interface with the name interfaceName in package packageName
-func findInterface(pkgs []*packages.Package, packageName, packageDirectory, interfaceName string) (findInterfaceResult, error) {
-	var astf []*ast.File
-	pkgFound := false
-	var thePackage *packages.Package
-	var isRootDir = packageDirectory == "." || packageDirectory == "./"
-	for _, pkg := range pkgs {
-		if pkg.Name == packageName && (strings.Contains(pkg.PkgPath, packageDirectory) || isRootDir) {
-			pkgFound = true
-			thePackage = pkg
-			for _, f := range pkg.Syntax {
-				astf = append(astf, f)
-			}
-			break
+	if *allPlatforms {
+		implementers := func(pkgs []*packages.Package) ([]inspector.Implementer, error) {
+			_, _, impls, err := queryImplementers(pkgs, scanPackages)
+			return impls, err
 		}
+		impls, err := platformImplementers(query, platforms, implementers)
+		if err != nil {
+			fmt.Printf("error: -all-platforms: %v\n", err)
+			return 1
+		}
+		if len(impls) == 0 {
+			fmt.Println("error: no types implement the interface on any platform")
+			return exitFailed
+		}
+		printPlatformImplementers(os.Stdout, impls, platforms)
+		return 0
+	}
+
+	if *serveAddr != "" {
+		fmt.Printf("serving on %s\n", *serveAddr)
+		err := serve(*serveAddr, &server{session: inspector.NewSession(query), scanPackages: scanPackages, jobs: *jobs})
+		fmt.Printf("error: -serve: %v\n", err)
+		return 1
 	}
 
-	if !pkgFound {
-		return findInterfaceResult{}, fmt.Errorf("couldn't find a package named %q in %q", packageName, packageDirectory)
+	// run answers the query from pkgs and returns the exit status, so that -watch can answer it again
+	run := func(pkgs []*packages.Package) int {
+		var err error
+		if *lang != "" {
+			packages.PrintErrors(pkgs)
+		}
+
+		if *printEnvironment {
+			var tags []string
+			if *buildTags != "" {
+				tags = strings.Split(*buildTags, ",")
+			}
+			info, err := collectEnv(pkgs, tags, query.Env)
+			if err == nil {
+				err = printEnv(os.Stdout, info, *format)
+			}
+			if err != nil {
+				fmt.Printf("error: -env: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		// the packages whose structs are scanned. The interface itself may live outside of them.
+		scanPkgs := pkgs
+		if ifacePkgPath != "" {
+			scanPkgs = inspector.DropExternalPackage(scanPkgs, ifacePkgPath)
+		}
+		scanPkgs = scanPackages(scanPkgs)
+
+		if *structScope != "" {
+			scanPkgs, err = inspector.FilterStructScope(scanPkgs, scopePattern, scopeIsDir)
+			if err != nil {
+				fmt.Printf("error: -struct-scope: %v\n", err)
+				return 1
+			}
+		}
+
+		if *directChildren != "" {
+			scanPkgs = inspector.FilterDirectChildren(scanPkgs, *directChildren)
+		}
+
+		if *module != "" {
+			scanPkgs = inspector.FilterModulePackages(scanPkgs, *module)
+		}
+
+		if len(parsedAssertions) > 0 && *format == "sarif" {
+			findings, err := assertionFindings(pkgs, parsedAssertions)
+			if err == nil {
+				err = printSARIF(os.Stdout, findings, ".")
+			}
+			if err != nil {
+				fmt.Printf("error: -assert: %v\n", err)
+				return 1
+			}
+			if len(findings) > 0 {
+				return exitFailed
+			}
+			return 0
+		}
+
+		if len(parsedAssertions) > 0 {
+			holds, err := checkAssertions(os.Stdout, pkgs, parsedAssertions)
+			if err != nil {
+				fmt.Printf("error: -assert: %v\n", err)
+				return 1
+			}
+			if !holds {
+				return exitFailed
+			}
+			return 0
+		}
+
+		if *batchMode {
+			if *format != "text" && *format != "json" && *format != "grep" && *format != "quickfix" {
+				fmt.Printf("error: -batch doesn't support -format %s\n", *format)
+				return 1
+			}
+			structs := func(pkgs []*packages.Package) []inspector.Struct {
+				return scanStructs(pkgs, scanOpts)
+			}
+			passed, err := runBatch(os.Stdin, os.Stdout, os.Stderr, scanPkgs, *format, structs)
+			if err != nil {
+				fmt.Printf("error: -batch: %v\n", err)
+				return 1
+			}
+			if !passed {
+				return exitFailed
+			}
+			return 0
+		}
+
+		if *replMode {
+			strcts := scanStructs(scanPkgs, scanOpts)
+			if err := runREPL(os.Stdin, os.Stdout, inspector.FindInterfaces(scanPkgs), strcts); err != nil {
+				fmt.Printf("error: -repl: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		if *listDuplicateInterfaces {
+			printDuplicateInterfaces(os.Stdout, inspector.FindDuplicateInterfaces(inspector.FindInterfaces(scanPkgs)))
+			return 0
+		}
+
+		if *listDeadInterfaces {
+			strcts := scanStructs(scanPkgs, scanOpts)
+			dead := inspector.FindDeadInterfaces(scanPkgs, strcts, inspector.FindInterfaces(scanPkgs))
+			if *format == "sarif" {
+				if err := printSARIF(os.Stdout, deadInterfaceFindings(dead), "."); err != nil {
+					fmt.Printf("error: encode findings: %v\n", err)
+					return 1
+				}
+				return 0
+			}
+			printDeadInterfaces(os.Stdout, dead)
+			return 0
+		}
+
+		if *listParamInterfaces {
+			printInterfacesUsedAsParams(os.Stdout, inspector.FindInterfacesUsedAsParams(scanPkgs))
+			return 0
+		}
+
+		if *structName != "" && !*generateStubs {
+			var strct inspector.Struct
+			if strings.Contains(*structName, ".") {
+				strct, err = inspector.FindStructByRef(pkgs, *structName)
+			} else {
+				strct, err = inspector.FindStruct(pkgs, *packageName, *packageDirectory, *structName)
+			}
+			if err != nil {
+				fmt.Printf("error: find struct: %v\n", err)
+				return 1
+			}
+			ifacePkgs := scanPkgs
+			if *stdlib {
+				ifacePkgs = append(ifacePkgs, inspector.StdlibPackages(scanPkgs)...)
+			}
+			satisfied := inspector.SatisfiedInterfaces(strct, inspector.FindInterfaces(ifacePkgs))
+			if *format == "json" {
+				if err := printSatisfiedJSON(os.Stdout, strct, satisfied); err != nil {
+					fmt.Printf("error: encode interfaces: %v\n", err)
+					return 1
+				}
+				if len(satisfied) == 0 {
+					return exitFailed
+				}
+				return 0
+			}
+			if len(satisfied) == 0 {
+				fmt.Printf("error: %q doesn't implement any interface of the scanned packages\n", *structName)
+				return exitFailed
+			}
+			printSatisfied(os.Stdout, satisfied)
+			return 0
+		}
+
+		var sizes types.Sizes
+		if *sizesCompiler != "" {
+			arch := *goarch
+			if arch == "" {
+				arch = runtime.GOARCH
+			}
+			sizes = types.SizesFor(*sizesCompiler, arch)
+			if sizes == nil {
+				fmt.Printf("error: unknown compiler/architecture %q/%q for -sizes\n", *sizesCompiler, arch)
+				return 1
+			}
+		}
+
+		if interfaceGlob != "" {
+			var ifaces []inspector.Interface
+			if *ifaceRef != "" {
+				ifaces, err = inspector.FindInterfacesMatchingRef(pkgs, *ifaceRef)
+			} else {
+				ifaces, err = inspector.FindInterfacesMatching(pkgs, *packageName, *packageDirectory, interfaceGlob)
+			}
+			if err != nil {
+				fmt.Printf("error: find interfaces: %v\n", err)
+				return 1
+			}
+
+			groups := groupImplementers(ifaces, scanStructs(scanPkgs, scanOpts), *allowEmpty, *includeUnexported, *receiver, *jobs)
+			switch {
+			case tmpl != nil:
+				for _, g := range groups {
+					if err = printTemplate(os.Stdout, tmpl, g.Implementers, g.Interface); err != nil {
+						break
+					}
+				}
+			case *format == "json":
+				err = printGroupedJSON(os.Stdout, groups)
+			case *format == "dot":
+				printDot(os.Stdout, groups, *dotEmbedded)
+			case *format == "grep" || *format == "quickfix":
+				for _, g := range groups {
+					printLocations(os.Stdout, g.Implementers, nil, g.Interface, *format == "quickfix")
+				}
+			default:
+				printGrouped(os.Stdout, groups)
+			}
+			if err != nil {
+				fmt.Printf("error: encode implementers: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		// search for the interface in the package
+		var iface inspector.Interface
+		if *ifaceRef != "" {
+			iface, err = inspector.FindInterfaceByRef(pkgs, *ifaceRef)
+		} else {
+			iface, err = inspector.FindInterface(pkgs, *packageName, *packageDirectory, *interfaceName)
+		}
+		if err != nil {
+			fmt.Printf("error: find interfaces: %v\n", err)
+			return 1
+		}
+
+		if *typeArgs != "" {
+			iface, err = inspector.Instantiate(iface, *typeArgs)
+			if err != nil {
+				fmt.Printf("error: -type-args: %v\n", err)
+				return 1
+			}
+		}
+
+		if !*includeUnexported {
+			iface.Type = inspector.ExportedMethodsOnly(iface.Type)
+		}
+
+		if *generateStubs {
+			var strct inspector.Struct
+			if strings.Contains(*structName, ".") {
+				strct, err = inspector.FindStructByRef(pkgs, *structName)
+			} else {
+				strct, err = inspector.FindStruct(pkgs, *packageName, *packageDirectory, *structName)
+			}
+			if err != nil {
+				fmt.Printf("error: find struct: %v\n", err)
+				return 1
+			}
+			stubs, err := inspector.GenerateStubs(strct, iface)
+			if err != nil {
+				fmt.Printf("error: -generate-stubs: %v\n", err)
+				return 1
+			}
+			if len(stubs.Source) == 0 {
+				fmt.Printf("%s already implements %s.%s\n", strct.Name, iface.Pkg.Name(), iface.Name)
+				return 0
+			}
+			if !*writeFile {
+				fmt.Printf("%s\n", stubs.Source)
+				return 0
+			}
+			if err := writeStubs(strct.Position.Filename, stubs); err != nil {
+				fmt.Printf("error: -w: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		if *describeMethods {
+			printInterfaceMethods(os.Stdout, pkgs, iface)
+			return 0
+		}
+
+		if *embeddingTree {
+			printEmbeddingTree(os.Stdout, inspector.EmbeddingTree(pkgs, iface), 0)
+			return 0
+		}
+
+		if *listEmbedders {
+			printEmbedders(os.Stdout, inspector.FindEmbedders(inspector.FindInterfaces(scanPkgs), iface))
+			return 0
+		}
+
+		if *showCost {
+			printImplementationCost(os.Stdout, iface)
+			return 0
+		}
+
+		if *packageConformance != "" {
+			conformances, err := inspector.CheckPackageConformance(pkgs, *packageConformance, iface)
+			if err != nil {
+				fmt.Printf("error: -package-conformance: %v\n", err)
+				return 1
+			}
+			printConformance(os.Stdout, conformances)
+			return 0
+		}
+
+		if *listRegistrations {
+			printRegistrations(os.Stdout, inspector.FindRegistrations(scanPkgs, strings.Split(*registerFuncs, ","), iface))
+			return 0
+		}
+
+		// every type implements the empty interface, which would list all of them
+		if iface.Type.Empty() && !*allowEmpty {
+			fmt.Printf("error: the interface %q has no methods and is implemented by every type, pass -allow-empty to list them anyway\n", iface.Name)
+			return 1
+		}
+
+		// find structs
+		strcts := scanStructs(scanPkgs, scanOpts)
+		if *why != "" {
+			explained := false
+			for _, strct := range strcts {
+				if strct.Obj.Name() == *why || inspector.QualifiedName(strct.Obj) == *why {
+					printExplanation(os.Stdout, inspector.Explain(strct, iface), iface)
+					explained = true
+				}
+			}
+			if !explained {
+				fmt.Printf("error: -why: no type %q in the scanned packages\n", *why)
+				return 1
+			}
+			return 0
+		}
+		if *compareRef != "" {
+			other, err := inspector.FindInterfaceByRef(pkgs, *compareRef)
+			if err != nil {
+				fmt.Printf("error: -compare: %v\n", err)
+				return 1
+			}
+			if !*includeUnexported {
+				other.Type = inspector.ExportedMethodsOnly(other.Type)
+			}
+			printComparison(os.Stdout, inspector.CompareInterfaces(iface, other, strcts))
+			return 0
+		}
+		if *instantiations {
+			printInstantiatedImplementers(os.Stdout, inspector.FindInstantiatedImplementers(scanPkgs, strcts, iface))
+			return 0
+		}
+		if *printNearMissJSON {
+			if err := printNearMissesJSON(os.Stdout, inspector.FindNearMisses(strcts, iface)); err != nil {
+				fmt.Printf("error: encode near misses: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		strctsImplementingIface := inspector.ParallelImplementers(strcts, iface, *jobs)
+		if *onlyStubs || *excludeStubs {
+			decls := inspector.IndexFuncDecls(scanPkgs)
+			kept := make([]inspector.Implementer, 0, len(strctsImplementingIface))
+			for _, strct := range strctsImplementingIface {
+				if inspector.IsStubImplementer(strct.Struct, iface, decls) == *onlyStubs {
+					kept = append(kept, strct)
+				}
+			}
+			strctsImplementingIface = kept
+		}
+		if *receiver != "any" {
+			strctsImplementingIface = filterReceiver(strctsImplementingIface, inspector.ReceiverKind(*receiver))
+		}
+		if *checkAssignable {
+			printDiscrepancies(os.Stdout, inspector.AssignabilityDiscrepancies(strcts, iface))
+		}
+		var nearMisses []inspector.NearMiss
+		if *showNearMisses || *format == "sarif" {
+			nearMisses = inspector.FindNearMisses(strcts, iface)
+		}
+		// the allowlist is checked before anything is printed, so that no output format skips it
+		var unapproved []inspector.Implementer
+		if *allowlist != "" && len(strctsImplementingIface) > 0 {
+			if *updateAllowlist {
+				if err := writeAllowlist(*allowlist, strctsImplementingIface); err != nil {
+					fmt.Printf("error: update allowlist: %v\n", err)
+					return 1
+				}
+				return 0
+			}
+
+			allowed, err := readAllowlist(*allowlist)
+			if err != nil {
+				fmt.Printf("error: read allowlist: %v\n", err)
+				return 1
+			}
+			unapproved = unapprovedImplementers(strctsImplementingIface, allowed)
+		}
+
+		if *count {
+			fmt.Printf("implementers: %d\n", len(strctsImplementingIface))
+			if *showNearMisses {
+				fmt.Printf("near misses: %d\n", len(nearMisses))
+			}
+			printUnapproved(os.Stdout, unapproved)
+			if len(strctsImplementingIface) == 0 || len(unapproved) > 0 {
+				return exitFailed
+			}
+			return 0
+		}
+
+		if *format == "sarif" {
+			findings := append(queryFindings(strctsImplementingIface, nearMisses, iface), unapprovedFindings(unapproved, iface)...)
+			if err := printSARIF(os.Stdout, findings, "."); err != nil {
+				fmt.Printf("error: encode findings: %v\n", err)
+				return 1
+			}
+			if len(strctsImplementingIface) == 0 || len(unapproved) > 0 {
+				return exitFailed
+			}
+			return 0
+		}
+
+		if len(strctsImplementingIface) == 0 {
+			if *format == "json" {
+				printJSON(os.Stdout, strctsImplementingIface, iface)
+				return exitFailed
+			}
+			if tmpl != nil {
+				return exitFailed
+			}
+			fmt.Printf("error: no types implement the interface %q defined in package %q\n", iface.Name, iface.Pkg.Name())
+			if len(nearMisses) > 0 {
+				fmt.Println("\nnear misses:")
+				printNearMisses(os.Stdout, nearMisses)
+			}
+			if *showUsages {
+				fmt.Println("\nusages:")
+				printUsages(os.Stdout, inspector.FindUsages(scanPkgs, []inspector.Interface{iface}))
+			}
+			return exitFailed
+		}
+
+		if len(unapproved) > 0 {
+			printUnapproved(os.Stdout, unapproved)
+			return exitFailed
+		}
+
+		strctsImplementingIface, err = inspector.SortAndLimit(strctsImplementingIface, *sortBy, *limit)
+		if err != nil {
+			fmt.Printf("error: -sort: %v\n", err)
+			return 1
+		}
+
+		if *format == "json" {
+			if err := printJSON(os.Stdout, strctsImplementingIface, iface); err != nil {
+				fmt.Printf("error: encode implementers: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		if tmpl != nil {
+			if err := printTemplate(os.Stdout, tmpl, strctsImplementingIface, iface); err != nil {
+				fmt.Printf("error: -format: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		if *format == "go-slice" {
+			printGoSlice(os.Stdout, strctsImplementingIface)
+			return 0
+		}
+
+		if *format == "plantuml" {
+			printPlantUML(os.Stdout, strctsImplementingIface, iface, *plantumlMethods)
+			return 0
+		}
+
+		if *format == "grep" || *format == "quickfix" {
+			printLocations(os.Stdout, strctsImplementingIface, nearMisses, iface, *format == "quickfix")
+			return 0
+		}
+
+		if *format == "dot" {
+			printDot(os.Stdout, []interfaceImplementers{{Interface: iface, Implementers: strctsImplementingIface}}, *dotEmbedded)
+			return 0
+		}
+
+		if *groupByEmbedded {
+			printGroupedByEmbedded(os.Stdout, strctsImplementingIface, iface)
+			return 0
+		}
+
+		lineWidth := *width
+		if lineWidth == 0 {
+			lineWidth, _ = terminalWidth(os.Stdout)
+		}
+		if *noTruncate {
+			lineWidth = 0
+		}
+
+		if *verbose {
+			fmt.Printf("language version: %s\n", iface.GoVersion)
+		}
+
+		for _, strct := range strctsImplementingIface {
+			detail := ""
+			if sizes != nil {
+				detail = sizeDetail(sizes, strct.Struct)
+			}
+			fmt.Println(formatResult(strct, detail, lineWidth))
+			if *showMethods {
+				printMethods(os.Stdout, pkgs[0].Fset, strct, iface)
+			} else if *verbose {
+				printReceivers(os.Stdout, strct, iface)
+			}
+		}
+
+		if len(nearMisses) > 0 {
+			fmt.Println("\nnear misses:")
+			printNearMisses(os.Stdout, nearMisses)
+		}
+		if *showUsages {
+			fmt.Println("\nusages:")
+			printUsages(os.Stdout, inspector.FindUsages(scanPkgs, []inspector.Interface{iface}))
+		}
+		return 0
 	}
 
-	scope := thePackage.Types.Scope()
+	if *watchMode {
+		if err := watch(os.Stdout, ".", inspector.NewSession(query), run); err != nil {
+			fmt.Printf("error: -watch: %v\n", err)
+			return 1
+		}
+		return 0
+	}
 
-	interfaceType := scope.Lookup(interfaceName)
-	if interfaceType == nil {
-		return findInterfaceResult{}, fmt.Errorf("no such interface %q in package %q", interfaceName, packageName)
+	pkgs, err := inspector.Load(context.Background(), query)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return 1
 	}
+	return run(pkgs)
+}
 
-	theInterface, ok := interfaceType.Type().Underlying().(*types.Interface)
-	if !ok {
-		return findInterfaceResult{}, fmt.Errorf("no such interface %q in package %q", interfaceName, packageName)
+// scanOptions tells scanStructs which types to find.
+type scanOptions struct {
+	jobs int
+	// testsOnly only keeps the types of _test.go files.
+	testsOnly bool
+	// aliases adds the type aliases, and locals the types declared inside functions.
+	aliases bool
+	locals  bool
+	filter  inspector.StructFilter
+}
+
+// scanStructs finds the types of pkgs that may implement an interface, see inspector.FindStructs, as opts tells.
+func scanStructs(pkgs []*packages.Package, opts scanOptions) []inspector.Struct {
+	strcts := inspector.FindStructs(pkgs, opts.jobs)
+	if opts.locals {
+		strcts = inspector.SortStructs(append(strcts, inspector.FindLocalStructs(pkgs)...))
+	}
+	if opts.testsOnly {
+		strcts = inspector.TestStructs(strcts)
+	}
+	if opts.aliases {
+		strcts = inspector.SortStructs(append(strcts, inspector.FindAliases(pkgs)...))
 	}
+	return inspector.FilterStructs(strcts, opts.filter)
+}
 
-	return findInterfaceResult{pkg: *thePackage.Types, iface: theInterface, ifaceName: interfaceName}, nil
+// packageFilter tells which of the loaded packages are scanned, see packageFilter.scanPackages.
+type packageFilter struct {
+	includeVendor bool
+	// deps adds the dependencies of the modules with the path prefixes depsModules, or of all of them
+	deps        bool
+	depsModules []string
+	// excludes are the globs of the import paths or directories of the packages not to scan
+	excludes []string
+	// gitignore skips the directories ignored by the .gitignore file of the repository
+	gitignore bool
 }
 
-// getStrctsImplementingIface returns all structs from strcts that implement the interface iface
-func getStrctsImplementingIface(path string, strcts []strctFound, iface findInterfaceResult) []strctFound {
-	strctResult := make([]strctFound, 0)
-	for _, strct := range strcts {
-		ptr := types.NewPointer(strct.obj.Type())
-		if types.Implements(ptr, iface.iface) {
-			strctResult = append(strctResult, strct)
+// scanPackages returns a function selecting the packages to scan among packages loaded from the directory root.
+// It drops the vendored, excluded and ignored packages and adds the dependencies with deps. The directories of
+// excludes and the .gitignore file are those of root, which differs from the working directory for another
+// revision checked out elsewhere. The globs of excludes must have been checked, see FilterExcludedPackages.
+func (f packageFilter) scanPackages(root string) (func(pkgs []*packages.Package) []*packages.Package, error) {
+	var gi *inspector.Gitignore
+	if f.gitignore {
+		var err error
+		gi, err = inspector.LoadGitignore(root)
+		if err != nil {
+			return nil, err
 		}
 	}
-
-	return strctResult
+	return func(pkgs []*packages.Package) []*packages.Package {
+		scanPkgs := pkgs
+		if !f.includeVendor {
+			scanPkgs = inspector.FilterVendorPackages(scanPkgs)
+		}
+		if f.deps {
+			scanPkgs = append(scanPkgs, inspector.DependencyPackages(pkgs, f.depsModules)...)
+		}
+		scanPkgs, _ = inspector.FilterExcludedPackages(scanPkgs, root, f.excludes)
+		if gi != nil {
+			scanPkgs = inspector.FilterIgnoredPackages(scanPkgs, gi)
+		}
+		return scanPkgs
+	}, nil
 }
 
-// findStrcts finds all structs in the project.
-func findStrcts(pkgs []*packages.Package) []strctFound {
-	strcts := make([]strctFound, 0)
-	for _, pkg := range pkgs {
-		scope := pkg.Types.Scope()
-		for _, name := range scope.Names() {
-			obj := scope.Lookup(name)
-			theStruct, ok := obj.Type().Underlying().(*types.Struct)
-			if ok {
-				strcts = append(strcts, strctFound{
-					obj:      obj,
-					strct:    *theStruct,
-					name:     obj.Name(),
-					position: pkg.Fset.Position(obj.Pos())})
-			}
+// groupImplementers finds the implementers of each of ifaces among strcts, for the interfaces matched by a glob.
+// Like a single interface, an interface is compared by its exported methods unless includeUnexported, and is
+// skipped if it has no methods then, unless allowEmpty.
+func groupImplementers(ifaces []inspector.Interface, strcts []inspector.Struct, allowEmpty, includeUnexported bool, receiver string, jobs int) []interfaceImplementers {
+	groups := make([]interfaceImplementers, 0, len(ifaces))
+	for _, iface := range ifaces {
+		if !includeUnexported {
+			iface.Type = inspector.ExportedMethodsOnly(iface.Type)
 		}
+		// every type implements an empty interface
+		if iface.Type.Empty() && !allowEmpty {
+			continue
+		}
+		impls := inspector.ParallelImplementers(strcts, iface, jobs)
+		if receiver != "any" {
+			impls = filterReceiver(impls, inspector.ReceiverKind(receiver))
+		}
+		groups = append(groups, interfaceImplementers{Interface: iface, Implementers: impls})
+	}
+	return groups
+}
 
+// filterReceiver keeps the implementers satisfying the interface the way receiver tells.
+func filterReceiver(impls []inspector.Implementer, receiver inspector.ReceiverKind) []inspector.Implementer {
+	kept := make([]inspector.Implementer, 0, len(impls))
+	for _, impl := range impls {
+		if impl.Receiver == receiver {
+			kept = append(kept, impl)
+		}
 	}
+	return kept
+}
+
+// isGlob reports whether name is a glob rather than the name of an interface.
+func isGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
 
-	return strcts
+// removeOnSignal removes path when the program is interrupted, since the deferred calls don't run then.
+func removeOnSignal(path string) {
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupted
+		os.RemoveAll(path)
+		os.Exit(exitInterrupted)
+	}()
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// loadSelfTest loads the self-test fixtures, see writeSelfTestModule.
+func loadSelfTest(t *testing.T) []*packages.Package {
+	t.Helper()
+	dir := t.TempDir()
+	if err := writeSelfTestModule(dir); err != nil {
+		t.Fatal(err)
+	}
+	return load(t, inspector.Query{Dir: dir})
+}
+
+// loadModule writes files, keyed by their slash separated path, into a module named example.com/m
+// and loads it.
+func loadModule(t *testing.T, files map[string]string) []*packages.Package {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/m\n\ngo 1.22\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return load(t, inspector.Query{Dir: dir})
+}
+
+func load(t *testing.T, q inspector.Query) []*packages.Package {
+	t.Helper()
+	pkgs, err := inspector.Load(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("the fixtures don't compile")
+	}
+	return pkgs
+}
+
+// findStruct returns the type named name among strcts.
+func findStruct(t *testing.T, strcts []inspector.Struct, name string) inspector.Struct {
+	t.Helper()
+	for _, strct := range strcts {
+		if strct.Name == name {
+			return strct
+		}
+	}
+	t.Fatalf("no type %s in the fixtures", name)
+	return inspector.Struct{}
+}
+
+func TestSizeDetail(t *testing.T) {
+	strcts := inspector.FindStructs(loadSelfTest(t), 0)
+	sizes := types.SizesFor("gc", "amd64")
+	for _, tt := range []struct {
+		name, want string
+	}{
+		{"circle", "size=8 fields=1"},
+		{"areaFunc", "size=8"},
+		// the size of a generic type depends on its type arguments
+		{"box[T]", "size=? fields=1"},
+	} {
+		if got := sizeDetail(sizes, findStruct(t, strcts, tt.name)); got != tt.want {
+			t.Errorf("sizeDetail(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrintReceivers(t *testing.T) {
+	pkgs := loadModule(t, map[string]string{"p/p.go": `package p
+
+type Doer interface {
+	A()
+	B()
+}
+
+type byValue struct{}
+
+func (byValue) A() {}
+func (byValue) B() {}
+
+type byPointer struct{}
+
+func (*byPointer) A() {}
+func (*byPointer) B() {}
+
+type mixed struct{}
+
+func (mixed) A()  {}
+func (*mixed) B() {}
+`})
+	iface, err := inspector.FindInterfaceByRef(pkgs, "example.com/m/p.Doer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for _, impl := range inspector.Implementers(inspector.FindStructs(pkgs, 0), iface) {
+		buf.WriteString(impl.Label() + "\n")
+		printReceivers(&buf, impl, iface)
+	}
+	want := `byValue (value receiver)
+	A: value receiver
+	B: value receiver
+byPointer (pointer receiver)
+	A: pointer receiver
+	B: pointer receiver
+mixed (pointer receiver)
+	A: value receiver
+	B: pointer receiver
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// TestSelfTest runs the cases of testdata/selftest/cases.txt, like -self-test.
+func TestSelfTest(t *testing.T) {
+	var buf bytes.Buffer
+	passed, err := runSelfTest(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !passed {
+		t.Errorf("self-test failed:\n%s", buf.String())
+	}
+}
+
+func TestGroupImplementers(t *testing.T) {
+	pkgs := loadModule(t, map[string]string{
+		"p/p.go": `package p
+
+type Sealed interface{ sealed() }
+
+type Shower interface{ Show() }
+
+type Mixed interface {
+	Show()
+	sealed()
+}
+
+type T struct{}
+
+func (T) Show()   {}
+func (T) sealed() {}
+`,
+	})
+	ifaces, err := inspector.FindInterfacesMatchingRef(pkgs, "example.com/m/p.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strcts := inspector.FindStructs(pkgs, 0)
+	groupNames := func(groups []interfaceImplementers) []string {
+		var names []string
+		for _, g := range groups {
+			names = append(names, g.Interface.Name)
+		}
+		return names
+	}
+
+	// Sealed has no exported methods, it is empty once they are the only ones compared
+	if got, want := groupNames(groupImplementers(ifaces, strcts, false, false, "any", 1)), []string{"Shower", "Mixed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := groupNames(groupImplementers(ifaces, strcts, true, false, "any", 1)), []string{"Sealed", "Shower", "Mixed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with -allow-empty: got %v, want %v", got, want)
+	}
+	if got, want := groupNames(groupImplementers(ifaces, strcts, false, true, "any", 1)), []string{"Sealed", "Shower", "Mixed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with -include-unexported: got %v, want %v", got, want)
+	}
+}
+
+// runMain runs the command with args in the current directory and returns what it printed and its exit code.
+func runMain(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	stdout, osArgs, commandLine := os.Stdout, os.Args, flag.CommandLine
+	defer func() { os.Stdout, os.Args, flag.CommandLine = stdout, osArgs, commandLine }()
+	os.Stdout, os.Args = out, append([]string{"interface-inspector"}, args...)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	code := realMain()
+	printed, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(printed), code
+}
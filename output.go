@@ -0,0 +1,613 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+const ellipsis = "…"
+
+// formatResult formats the result line of strct. detail, if any, is placed between the label and the position.
+// When width is positive, what follows the name of the type, its receiver kind and the detail, is shortened with
+// an ellipsis so that the line fits in width columns. The name and the position are never truncated: if they
+// leave no room for more, that is all replaced by the ellipsis and the line is wider than width.
+func formatResult(strct inspector.Implementer, detail string, width int) string {
+	rest := strings.TrimPrefix(strct.Label(), strct.Name)
+	if detail != "" {
+		rest += " " + detail
+	}
+	position := strct.PositionString()
+	if width > 0 {
+		room := width - utf8.RuneCountInString(strct.Name) - utf8.RuneCountInString(position) - 1
+		rest = truncate(rest, room)
+		if rest == "" {
+			rest = " " + ellipsis
+		}
+	}
+	return strct.Name + rest + " " + position
+}
+
+// sizeDetail describes the size of strct as laid out by sizes and, for structs, its number of fields.
+// The size of a generic type that isn't instantiated depends on its type arguments, so it's given as size=?.
+func sizeDetail(sizes types.Sizes, strct inspector.Struct) string {
+	detail := "size=?"
+	if named, ok := types.Unalias(strct.Obj.Type()).(*types.Named); !ok || named.TypeParams().Len() == named.TypeArgs().Len() {
+		detail = fmt.Sprintf("size=%d", sizes.Sizeof(strct.Type))
+	}
+	if fields, ok := strct.Type.(*types.Struct); ok {
+		detail += fmt.Sprintf(" fields=%d", fields.NumFields())
+	}
+	return detail
+}
+
+// truncate shortens s to at most n runes, ending it with an ellipsis when something was cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= utf8.RuneCountInString(ellipsis) {
+		return ""
+	}
+	runes := []rune(s)
+	return string(runes[:n-utf8.RuneCountInString(ellipsis)]) + ellipsis
+}
+
+// printGoSlice writes strcts as a Go slice literal of Impl values, preceded by the declaration of Impl,
+// so that the output can be compiled into another program.
+func printGoSlice(w io.Writer, strcts []inspector.Implementer) {
+	fmt.Fprintln(w, "type Impl struct {")
+	fmt.Fprintln(w, "\tName string")
+	fmt.Fprintln(w, "\tPkg  string")
+	fmt.Fprintln(w, "\tFile string")
+	fmt.Fprintln(w, "\tLine int")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "var Impls = []Impl{")
+	for _, strct := range strcts {
+		fmt.Fprintf(w, "\t{Name: %q, Pkg: %q, File: %q, Line: %d},\n",
+			strct.Name, strct.PkgPath, strct.Position.Filename, strct.Position.Line)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// printGroupedByEmbedded writes strcts grouped by the embedded types that provide the methods satisfying iface.
+// Structs that declare all the methods themselves are listed last.
+func printGroupedByEmbedded(w io.Writer, strcts []inspector.Implementer, iface inspector.Interface) {
+	groups := make(map[string][]inspector.Implementer)
+	keys := make([]string, 0)
+	for _, strct := range strcts {
+		key := strings.Join(inspector.EmbeddedProviders(strct.Struct, iface), ", ")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], strct)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		// the direct implementers have the empty key and go last
+		if keys[i] == "" || keys[j] == "" {
+			return keys[j] == ""
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		if key == "" {
+			fmt.Fprintf(w, "declared directly (%d):\n", len(groups[key]))
+		} else {
+			fmt.Fprintf(w, "via embedded %s (%d):\n", key, len(groups[key]))
+		}
+		for _, strct := range groups[key] {
+			fmt.Fprintf(w, "\t%s\n", strct.String())
+		}
+	}
+}
+
+// printPlantUML writes a PlantUML class diagram with iface and the structs of strcts realizing it.
+// Elements are named by their qualified names so that types with the same name in different packages
+// don't collide. withMethods adds the interface methods to the diagram.
+func printPlantUML(w io.Writer, strcts []inspector.Implementer, iface inspector.Interface, withMethods bool) {
+	fmt.Fprintln(w, "@startuml")
+	fmt.Fprintf(w, "interface %q as I", iface.QualifiedName())
+	if withMethods {
+		fmt.Fprintln(w, " {")
+		for i := 0; i < iface.Type.NumMethods(); i++ {
+			m := iface.Type.Method(i)
+			fmt.Fprintf(w, "  +%s%s\n", m.Name(), strings.TrimPrefix(types.TypeString(m.Type(), inspector.PackageNameQualifier), "func"))
+		}
+		fmt.Fprint(w, "}")
+	}
+	fmt.Fprintln(w)
+
+	for i, strct := range strcts {
+		fmt.Fprintf(w, "class %q as C%d\n", inspector.QualifiedName(strct.Obj), i)
+		fmt.Fprintf(w, "C%d ..|> I\n", i)
+	}
+	fmt.Fprintln(w, "@enduml")
+}
+
+// printDot writes a Graphviz digraph of the interfaces of groups and their implementers, with an edge from every
+// implementer to the interface. Implementers satisfying it through a pointer only have their edge labelled *.
+// With embedded, the interfaces the interfaces embed, directly or not, are added with edges to them.
+func printDot(w io.Writer, groups []interfaceImplementers, embedded bool) {
+	fmt.Fprintln(w, "digraph implementations {")
+	fmt.Fprintln(w, "\trankdir=BT;")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	seen := make(map[string]bool)
+	node := func(id, label, attrs string) {
+		if !seen[id] {
+			seen[id] = true
+			fmt.Fprintf(w, "\t%q [label=%q%s];\n", id, label, attrs)
+		}
+	}
+	// embeddings adds the interfaces embedded by t with edges from t, whose node is id
+	var embeddings func(id string, t *types.Interface)
+	embeddings = func(id string, t *types.Interface) {
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			named, ok := types.Unalias(t.EmbeddedType(i)).(*types.Named)
+			if !ok {
+				continue
+			}
+			embeddedID := inspector.QualifiedName(named.Obj())
+			if !seen[embeddedID] {
+				node(embeddedID, types.TypeString(named, inspector.PackageNameQualifier), ", style=rounded")
+				if iface, ok := named.Underlying().(*types.Interface); ok {
+					embeddings(embeddedID, iface)
+				}
+			}
+			fmt.Fprintf(w, "\t%q -> %q [style=dashed, label=\"embeds\"];\n", id, embeddedID)
+		}
+	}
+
+	for _, g := range groups {
+		ifaceID := g.Interface.QualifiedName()
+		node(ifaceID, g.Interface.Pkg.Name()+"."+g.Interface.Name, ", style=rounded")
+		if embedded && g.Interface.Named != nil {
+			if iface, ok := g.Interface.Named.Underlying().(*types.Interface); ok {
+				embeddings(ifaceID, iface)
+			}
+		}
+		for _, impl := range g.Implementers {
+			id := inspector.QualifiedName(impl.Obj)
+			node(id, impl.Obj.Pkg().Name()+"."+impl.Name, "")
+			if impl.Receiver == inspector.PointerReceiver {
+				fmt.Fprintf(w, "\t%q -> %q [label=\"*\"];\n", id, ifaceID)
+			} else {
+				fmt.Fprintf(w, "\t%q -> %q;\n", id, ifaceID)
+			}
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// printLocations writes one file:line:col: message line per implementer and near miss, the format of grep -n
+// and the default errorformat of Vim. With severity, the messages start with info, or warning for the near misses,
+// like the output of compilers that VS Code problem matchers expect.
+func printLocations(w io.Writer, strcts []inspector.Implementer, nearMisses []inspector.NearMiss, iface inspector.Interface, severity bool) {
+	name := iface.Pkg.Name() + "." + iface.Name
+	for _, strct := range strcts {
+		pos := strct.Position
+		level := ""
+		if severity {
+			level = "info: "
+		}
+		fmt.Fprintf(w, "%s:%d:%d: %s%s implements %s\n", pos.Filename, pos.Line, pos.Column, level, strct.Label(), name)
+	}
+	for _, nm := range nearMisses {
+		pos := nm.Position
+		level := ""
+		if severity {
+			level = "warning: "
+		}
+		fmt.Fprintf(w, "%s:%d:%d: %s%s doesn't implement %s: %s\n", pos.Filename, pos.Line, pos.Column, level, nm.Name, name,
+			missingSummary(nm.Missing))
+	}
+}
+
+type strctJSON struct {
+	Name        string `json:"name"`
+	PackagePath string `json:"package_path"`
+	Filename    string `json:"filename"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Type        string `json:"type"`
+	// Kind is "struct" for structs and describes the underlying type otherwise, like "func" or "slice"
+	Kind string `json:"kind"`
+	// Receiver is "value" if the value satisfies the interface and "pointer" if only a pointer to it does
+	Receiver string `json:"receiver"`
+	// Interface is the qualified name of the implemented interface
+	Interface string `json:"interface"`
+}
+
+// printJSON writes strcts, the implementers of iface, as a JSON array. No structs result in an empty array.
+func printJSON(w io.Writer, strcts []inspector.Implementer, iface inspector.Interface) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(implementersToJSON(strcts, iface))
+}
+
+func implementersToJSON(strcts []inspector.Implementer, iface inspector.Interface) []strctJSON {
+	result := make([]strctJSON, 0, len(strcts))
+	for _, strct := range strcts {
+		result = append(result, toJSON(strct, iface))
+	}
+	return result
+}
+
+// printSatisfiedJSON writes the interfaces implemented by strct as a JSON array of the same objects as printJSON.
+func printSatisfiedJSON(w io.Writer, strct inspector.Struct, satisfied []inspector.Satisfied) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(satisfiedToJSON(strct, satisfied))
+}
+
+func satisfiedToJSON(strct inspector.Struct, satisfied []inspector.Satisfied) []strctJSON {
+	result := make([]strctJSON, 0, len(satisfied))
+	for _, s := range satisfied {
+		result = append(result, toJSON(inspector.Implementer{Struct: strct, Receiver: s.Receiver}, s.Interface))
+	}
+	return result
+}
+
+func toJSON(strct inspector.Implementer, iface inspector.Interface) strctJSON {
+	return strctJSON{
+		Name:        strct.Name,
+		PackagePath: strct.PkgPath,
+		Filename:    strct.Position.Filename,
+		Line:        strct.Position.Line,
+		Column:      strct.Position.Column,
+		Type:        types.TypeString(strct.Type, inspector.PackageNameQualifier),
+		Kind:        strct.Kind,
+		Receiver:    string(strct.Receiver),
+		Interface:   iface.QualifiedName(),
+	}
+}
+
+// printReceivers writes the per method receiver breakdown of strct, indented under its result line.
+func printReceivers(w io.Writer, strct inspector.Implementer, iface inspector.Interface) {
+	for _, r := range inspector.MethodReceivers(strct.Struct, iface) {
+		kind := "value receiver"
+		if r.Pointer {
+			kind = "pointer receiver"
+		}
+		fmt.Fprintf(w, "\t%s: %s\n", r.Method.Name(), kind)
+	}
+}
+
+// printMethods writes, for every interface method, the method of strct that satisfies it with its receiver and
+// where it is declared, telling apart the methods strct declares from the ones promoted through embedded fields.
+// Promoted methods are followed by the chain of embedded fields they are promoted through.
+func printMethods(w io.Writer, fset *token.FileSet, strct inspector.Implementer, iface inspector.Interface) {
+	for _, r := range inspector.MethodReceivers(strct.Struct, iface) {
+		recv := r.Method.Type().(*types.Signature).Recv().Type()
+		signature := strings.TrimPrefix(types.TypeString(r.Method.Type(), inspector.PackageNameQualifier), "func")
+		origin := "declared"
+		if r.PromotedFrom != nil {
+			names := make([]string, 0, len(r.Embeddings))
+			for _, field := range r.Embeddings {
+				names = append(names, field.Name())
+			}
+			origin = fmt.Sprintf("promoted from %s through %s.%s",
+				types.TypeString(r.PromotedFrom, inspector.PackageNameQualifier), strct.Obj.Name(), strings.Join(names, "."))
+		}
+		pos := fset.Position(r.Method.Pos())
+		fmt.Fprintf(w, "\t%s: func (%s) %s%s, %s at %s:%d:%d\n",
+			r.Method.Name(), types.TypeString(recv, inspector.PackageNameQualifier), r.Method.Name(), signature, origin,
+			pos.Filename, pos.Line, pos.Column)
+	}
+}
+
+// printDiscrepancies writes a warning for every type whose assignability differs from its implementation of the interface.
+func printDiscrepancies(w io.Writer, discrepancies []inspector.Discrepancy) {
+	for _, d := range discrepancies {
+		fmt.Fprintf(w, "warning: assignability differs from implementation: %s %s implements=%t assignable=%t\n",
+			types.TypeString(d.Type, inspector.PackageNameQualifier), d.PositionString(), d.Implements, d.Assignable)
+	}
+}
+
+// printInterfaceMethods writes the method set of iface, including the methods of embedded interfaces,
+// each followed by its doc comment when it can be found in the loaded packages.
+func printInterfaceMethods(w io.Writer, pkgs []*packages.Package, iface inspector.Interface) {
+	for i := 0; i < iface.Type.NumMethods(); i++ {
+		m := iface.Type.Method(i)
+		signature := strings.TrimPrefix(types.TypeString(m.Type(), inspector.PackageNameQualifier), "func")
+		if m.Pkg() != nil && m.Pkg().Path() != iface.Pkg.Path() {
+			fmt.Fprintf(w, "%s%s (from package %s)\n", m.Name(), signature, m.Pkg().Path())
+		} else {
+			fmt.Fprintf(w, "%s%s\n", m.Name(), signature)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(inspector.MethodDoc(pkgs, m)), "\n") {
+			if line != "" {
+				fmt.Fprintf(w, "\t%s\n", line)
+			}
+		}
+	}
+}
+
+// printEmbeddingTree writes node, the methods it declares and, indented, the interfaces it embeds.
+func printEmbeddingTree(w io.Writer, node inspector.EmbeddingNode, depth int) {
+	indent := strings.Repeat("\t", depth)
+	if node.Origin != "" {
+		fmt.Fprintf(w, "%s%s [%s]\n", indent, node.Name, node.Origin)
+	} else {
+		fmt.Fprintf(w, "%s%s\n", indent, node.Name)
+	}
+	for _, m := range node.Methods {
+		fmt.Fprintf(w, "%s\t%s%s\n", indent, m.Name(),
+			strings.TrimPrefix(types.TypeString(m.Type(), inspector.PackageNameQualifier), "func"))
+	}
+	for _, embedded := range node.Embedded {
+		printEmbeddingTree(w, embedded, depth+1)
+	}
+}
+
+// printEmbedders writes one line per interface embedding another one, with the interfaces it's embedded through.
+func printEmbedders(w io.Writer, embedders []inspector.Embedder) {
+	for _, e := range embedders {
+		fmt.Fprintf(w, "%s %s:%d:%d", e.QualifiedName(), e.Position.Filename, e.Position.Line, e.Position.Column)
+		if len(e.Via) > 0 {
+			fmt.Fprintf(w, " via %s", strings.Join(e.Via, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printImplementationCost writes the number of methods of iface and the distinct types in their signatures.
+func printImplementationCost(w io.Writer, iface inspector.Interface) {
+	methods, typeNames := inspector.ImplementationCost(iface)
+	fmt.Fprintf(w, "methods: %d\n", methods)
+	fmt.Fprintf(w, "distinct types: %d", len(typeNames))
+	if len(typeNames) > 0 {
+		fmt.Fprintf(w, " (%s)", strings.Join(typeNames, ", "))
+	}
+	fmt.Fprintln(w)
+}
+
+type nearMissJSON struct {
+	Name        string       `json:"name"`
+	Position    string       `json:"position"`
+	Missing     []methodJSON `json:"missing"`
+	Implemented []methodJSON `json:"implemented"`
+}
+
+type methodJSON struct {
+	Method    string `json:"method"`
+	Signature string `json:"signature"`
+}
+
+// printNearMissesJSON writes nearMisses as a JSON array.
+func printNearMissesJSON(w io.Writer, nearMisses []inspector.NearMiss) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nearMissesToJSON(nearMisses))
+}
+
+func nearMissesToJSON(nearMisses []inspector.NearMiss) []nearMissJSON {
+	result := make([]nearMissJSON, 0, len(nearMisses))
+	for _, nm := range nearMisses {
+		result = append(result, nearMissJSON{
+			Name:        nm.Name,
+			Position:    nm.PositionString(),
+			Missing:     methodsToJSON(nm.Missing),
+			Implemented: methodsToJSON(nm.Implemented),
+		})
+	}
+	return result
+}
+
+func methodsToJSON(methods []inspector.MethodMatch) []methodJSON {
+	result := make([]methodJSON, 0, len(methods))
+	for _, m := range methods {
+		result = append(result, methodJSON{
+			Method:    m.Method.Name(),
+			Signature: types.TypeString(m.Method.Type(), inspector.PackageNameQualifier),
+		})
+	}
+	return result
+}
+
+// printNearMisses writes every near miss followed by the methods it lacks, telling apart
+// missing methods from methods with a wrong signature.
+func printNearMisses(w io.Writer, nearMisses []inspector.NearMiss) {
+	for _, nm := range nearMisses {
+		fmt.Fprintf(w, "%s\n", nm.String())
+		printMissing(w, nm.Missing)
+	}
+}
+
+// printMissing writes one indented line per missing method, telling apart the methods with a wrong signature.
+func printMissing(w io.Writer, missing []inspector.MethodMatch) {
+	for _, m := range missing {
+		want := types.TypeString(m.Method.Type(), inspector.PackageNameQualifier)
+		if m.WrongSignature {
+			have := types.TypeString(m.Have.Type(), inspector.PackageNameQualifier)
+			fmt.Fprintf(w, "\twrong signature %s: have %s, want %s\n", m.Method.Name(), have, want)
+		} else {
+			fmt.Fprintf(w, "\tmissing %s: %s\n", m.Method.Name(), want)
+		}
+	}
+}
+
+// printExplanation writes whether the type of e implements iface and, if it doesn't, what it lacks.
+func printExplanation(w io.Writer, e inspector.Explanation, iface inspector.Interface) {
+	name := iface.Pkg.Name() + "." + iface.Name
+	switch e.Receiver {
+	case inspector.ValueReceiver:
+		fmt.Fprintf(w, "%s implements %s\n", e.String(), name)
+		return
+	case inspector.PointerReceiver:
+		fmt.Fprintf(w, "%s doesn't implement %s, only *%s does\n", e.String(), name, e.Name)
+	default:
+		fmt.Fprintf(w, "%s doesn't implement %s\n", e.String(), name)
+	}
+	printMissing(w, e.Missing)
+	for _, fn := range e.PointerOnly {
+		fmt.Fprintf(w, "\tpointer receiver %s: only *%s has it\n", fn.Name(), e.Name)
+	}
+}
+
+// printComparison writes how the method sets of the compared interfaces relate, their methods by group
+// and the types implementing both.
+func printComparison(w io.Writer, c inspector.Comparison) {
+	a, b := c.A.Pkg.Name()+"."+c.A.Name, c.B.Pkg.Name()+"."+c.B.Name
+	switch c.Relation {
+	case inspector.Identical:
+		fmt.Fprintf(w, "%s and %s have identical method sets\n", a, b)
+	case inspector.Subset, inspector.Superset:
+		fmt.Fprintf(w, "%s is a %s of %s\n", a, c.Relation, b)
+	default:
+		fmt.Fprintf(w, "%s and %s are %s\n", a, b, c.Relation)
+	}
+	for _, m := range c.Common {
+		fmt.Fprintf(w, "\tcommon %s: %s\n", m.Name(), types.TypeString(m.Type(), inspector.PackageNameQualifier))
+	}
+	for _, m := range c.OnlyA {
+		fmt.Fprintf(w, "\tonly in %s %s: %s\n", a, m.Name(), types.TypeString(m.Type(), inspector.PackageNameQualifier))
+	}
+	for _, m := range c.OnlyB {
+		fmt.Fprintf(w, "\tonly in %s %s: %s\n", b, m.Name(), types.TypeString(m.Type(), inspector.PackageNameQualifier))
+	}
+	for _, m := range c.Different {
+		fmt.Fprintf(w, "\tdifferent %s: %s in %s, %s in %s\n", m.Method.Name(),
+			types.TypeString(m.Method.Type(), inspector.PackageNameQualifier), a,
+			types.TypeString(m.Have.Type(), inspector.PackageNameQualifier), b)
+	}
+
+	if len(c.Implementers) == 0 {
+		fmt.Fprintf(w, "no type implements both\n")
+		return
+	}
+	fmt.Fprintf(w, "implemented by both:\n")
+	for _, impl := range c.Implementers {
+		fmt.Fprintln(w, impl.String())
+	}
+}
+
+// printDuplicateInterfaces writes the groups of interfaces with identical method sets, separated by blank lines.
+func printDuplicateInterfaces(w io.Writer, groups [][]inspector.Interface) {
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		for _, iface := range group {
+			fmt.Fprintf(w, "%s %s:%d:%d\n", iface.QualifiedName(), iface.Position.Filename, iface.Position.Line, iface.Position.Column)
+		}
+	}
+}
+
+func printRegistrations(w io.Writer, registrations []inspector.Registration) {
+	for _, r := range registrations {
+		fmt.Fprintf(w, "%s registered at %s:%d:%d\n",
+			types.TypeString(r.Type, inspector.PackageNameQualifier), r.CallSite.Filename, r.CallSite.Line, r.CallSite.Column)
+	}
+}
+
+// printConformance writes one status line per checked type. Near misses list the methods they lack.
+func printConformance(w io.Writer, conformances []inspector.Conformance) {
+	for _, c := range conformances {
+		status := string(c.Status)
+		if len(c.Missing) > 0 {
+			names := make([]string, 0, len(c.Missing))
+			for _, m := range c.Missing {
+				names = append(names, m.Method.Name())
+			}
+			status += " (missing " + strings.Join(names, ", ") + ")"
+		}
+		fmt.Fprintf(w, "%s %s %s:%d:%d\n", c.Obj.Name(), status, c.Position.Filename, c.Position.Line, c.Position.Column)
+	}
+}
+
+// printUsages writes one line per usage with its kind and position.
+func printUsages(w io.Writer, usages []inspector.Usage) {
+	for _, u := range usages {
+		fmt.Fprintf(w, "%s %s:%d:%d\n", u.Kind, u.Position.Filename, u.Position.Line, u.Position.Column)
+	}
+}
+
+// printDeadInterfaces writes every dead interface with what makes it dead and its position.
+func printDeadInterfaces(w io.Writer, dead []inspector.DeadInterface) {
+	for _, d := range dead {
+		reasons := make([]string, 0, 2)
+		if d.Implementers == 0 {
+			reasons = append(reasons, "no implementers")
+		}
+		if d.Usages == 0 {
+			reasons = append(reasons, "unused")
+		}
+		fmt.Fprintf(w, "%s.%s %s %s:%d:%d\n", d.Pkg.Name(), d.Name, strings.Join(reasons, ", "),
+			d.Position.Filename, d.Position.Line, d.Position.Column)
+	}
+}
+
+func printInterfacesUsedAsParams(w io.Writer, interfaces []inspector.ParamInterface) {
+	for _, p := range interfaces {
+		name := inspector.QualifiedName(p.Obj)
+		if p.Position.IsValid() {
+			fmt.Fprintf(w, "%s %d %s:%d:%d\n", name, p.Uses, p.Position.Filename, p.Position.Line, p.Position.Column)
+		} else {
+			fmt.Fprintf(w, "%s %d\n", name, p.Uses)
+		}
+	}
+}
+
+// printSatisfied writes one line per interface implemented by the type given with -struct.
+func printSatisfied(w io.Writer, satisfied []inspector.Satisfied) {
+	for _, s := range satisfied {
+		fmt.Fprintf(w, "%s (%s receiver) %s:%d:%d\n", s.QualifiedName(), s.Receiver, s.Position.Filename, s.Position.Line, s.Position.Column)
+	}
+}
+
+// printInstantiatedImplementers writes every instantiation of the interface followed by its implementers, indented.
+func printInstantiatedImplementers(w io.Writer, instantiations []inspector.InstantiatedImplementers) {
+	if len(instantiations) == 0 {
+		fmt.Fprintln(w, "no instantiations found")
+		return
+	}
+	for _, inst := range instantiations {
+		fmt.Fprintf(w, "%s:\n", inst.Name)
+		for _, impl := range inst.Implementers {
+			fmt.Fprintf(w, "\t%s\n", impl)
+		}
+	}
+}
+
+// interfaceImplementers are the implementers of one of the interfaces matched by a glob.
+type interfaceImplementers struct {
+	Interface    inspector.Interface
+	Implementers []inspector.Implementer
+}
+
+// printGrouped writes every interface followed by its implementers, indented.
+func printGrouped(w io.Writer, groups []interfaceImplementers) {
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s.%s:\n", g.Interface.Pkg.Name(), g.Interface.Name)
+		for _, impl := range g.Implementers {
+			fmt.Fprintf(w, "\t%s\n", impl)
+		}
+	}
+}
+
+// printGroupedJSON writes the implementers of all the interfaces as a single JSON array of the same objects
+// as printJSON. Their interface field tells the interfaces apart.
+func printGroupedJSON(w io.Writer, groups []interfaceImplementers) error {
+	result := make([]strctJSON, 0)
+	for _, g := range groups {
+		for _, impl := range g.Implementers {
+			result = append(result, toJSON(impl, g.Interface))
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
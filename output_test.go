@@ -0,0 +1,67 @@
+package main
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"size=8 fields=1", 20, "size=8 fields=1"},
+		{"size=8 fields=1", 15, "size=8 fields=1"},
+		{"size=8 fields=1", 14, "size=8 fields…"},
+		{"size=8 fields=1", 2, "s…"},
+		// there is no room for anything but the ellipsis
+		{"size=8 fields=1", 1, ""},
+		{"size=8 fields=1", 0, ""},
+		{"size=8 fields=1", -3, ""},
+		// runes are counted, not bytes
+		{"größe=8", 7, "größe=8"},
+		{"größe=8", 4, "grö…"},
+		{"", 0, ""},
+	} {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	impl := inspector.Implementer{
+		Struct: inspector.Struct{
+			Name:     "postgresStore",
+			Kind:     "struct",
+			Position: token.Position{Filename: "db/pg.go", Line: 12, Column: 6},
+		},
+		Receiver: inspector.PointerReceiver,
+	}
+	for _, tt := range []struct {
+		detail string
+		width  int
+		want   string
+	}{
+		{"", 0, "postgresStore (pointer receiver) db/pg.go:12:6"},
+		{"size=24 fields=3", 0, "postgresStore (pointer receiver) size=24 fields=3 db/pg.go:12:6"},
+		{"size=24 fields=3", 200, "postgresStore (pointer receiver) size=24 fields=3 db/pg.go:12:6"},
+		// the detail is shortened first since it comes last
+		{"size=24 fields=3", 55, "postgresStore (pointer receiver) size=24… db/pg.go:12:6"},
+		// without details the receiver kind is shortened too
+		{"", 30, "postgresStore (… db/pg.go:12:6"},
+		{"size=24 fields=3", 30, "postgresStore (… db/pg.go:12:6"},
+		{"size=24 fields=3", 29, "postgresStore … db/pg.go:12:6"},
+		// the name and the position are never truncated, even if the line doesn't fit then
+		{"size=24 fields=3", 28, "postgresStore … db/pg.go:12:6"},
+		{"", 10, "postgresStore … db/pg.go:12:6"},
+		{"", 1, "postgresStore … db/pg.go:12:6"},
+	} {
+		if got := formatResult(impl, tt.detail, tt.width); got != tt.want {
+			t.Errorf("formatResult(%q, %d) = %q, want %q", tt.detail, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// platformImplementers loads the packages of query once per platform and merges the implementers that
+// implementers finds in each load.
+func platformImplementers(query inspector.Query, platforms []inspector.Platform,
+	implementers func([]*packages.Package) ([]inspector.Implementer, error)) ([]inspector.PlatformImplementer, error) {
+	env := query.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	impls := make([][]inspector.Implementer, 0, len(platforms))
+	for _, p := range platforms {
+		q := query
+		q.Env = p.Env(env)
+		pkgs, err := inspector.Load(context.Background(), q)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+		platformImpls, err := implementers(pkgs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+		impls = append(impls, platformImpls)
+	}
+	return inspector.MergePlatformImplementers(platforms, impls), nil
+}
+
+// printPlatformImplementers writes one line per implementer with the platforms it implements the interface on,
+// or all if it does on every checked platform.
+func printPlatformImplementers(w io.Writer, impls []inspector.PlatformImplementer, platforms []inspector.Platform) {
+	for _, impl := range impls {
+		annotation := "all"
+		if len(impl.Platforms) < len(platforms) {
+			names := make([]string, 0, len(impl.Platforms))
+			for _, p := range impl.Platforms {
+				names = append(names, p.String())
+			}
+			annotation = strings.Join(names, ", ")
+		}
+		fmt.Fprintf(w, "%s [%s]\n", impl.String(), annotation)
+	}
+}
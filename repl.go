@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+const replHelp = `Type part of an interface name to search the interfaces, fuzzily.
+Type the number of an interface in the list to show its implementers and near misses,
+or the number of an implementer to show the interfaces it implements.
+? prints this help, q quits.`
+
+// repl answers the commands of -repl from one scan of the packages.
+type repl struct {
+	w      io.Writer
+	ifaces []inspector.Interface
+	strcts []inspector.Struct
+
+	// the last listed interfaces or types, which the numbers typed refer to
+	listedIfaces []inspector.Interface
+	listedStrcts []inspector.Struct
+}
+
+// runREPL reads commands from r, line by line, and writes their results to w until r ends or q is typed.
+func runREPL(r io.Reader, w io.Writer, ifaces []inspector.Interface, strcts []inspector.Struct) error {
+	b := &repl{w: w, ifaces: ifaces, strcts: strcts}
+	fmt.Fprintf(w, "%d interfaces, %d types. ? for help\n", len(ifaces), len(strcts))
+
+	scanner := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(w, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(w)
+			return scanner.Err()
+		}
+		switch line := strings.TrimSpace(scanner.Text()); line {
+		case "":
+		case "q", "quit":
+			return nil
+		case "?", "help":
+			fmt.Fprintln(w, replHelp)
+		default:
+			if n, err := strconv.Atoi(line); err == nil {
+				b.open(n)
+			} else {
+				b.search(line)
+			}
+		}
+	}
+}
+
+// search lists the interfaces whose qualified name contains query, followed by the ones whose name contains
+// the characters of query in order, like fch for Fetcher.
+func (b *repl) search(query string) {
+	query = strings.ToLower(query)
+	substring := make([]inspector.Interface, 0)
+	fuzzy := make([]inspector.Interface, 0)
+	for _, iface := range b.ifaces {
+		switch {
+		case strings.Contains(strings.ToLower(iface.QualifiedName()), query):
+			substring = append(substring, iface)
+		case isSubsequence(query, strings.ToLower(iface.Name)):
+			fuzzy = append(fuzzy, iface)
+		}
+	}
+
+	b.listedIfaces, b.listedStrcts = append(substring, fuzzy...), nil
+	if len(b.listedIfaces) == 0 {
+		fmt.Fprintf(b.w, "no interface matches %q\n", query)
+		return
+	}
+	for i, iface := range b.listedIfaces {
+		fmt.Fprintf(b.w, "%3d %s.%s %s:%d\n", i+1, iface.Pkg.Name(), iface.Name, iface.Position.Filename, iface.Position.Line)
+	}
+}
+
+// open shows the n-th listed interface or type.
+func (b *repl) open(n int) {
+	switch {
+	case n >= 1 && n <= len(b.listedIfaces):
+		b.showInterface(b.listedIfaces[n-1])
+	case n >= 1 && n <= len(b.listedStrcts):
+		b.showStruct(b.listedStrcts[n-1])
+	default:
+		fmt.Fprintf(b.w, "no result %d in the last list\n", n)
+	}
+}
+
+// showInterface lists the implementers of iface, which can be opened next, and its near misses.
+func (b *repl) showInterface(iface inspector.Interface) {
+	fmt.Fprintf(b.w, "%s\n", iface.QualifiedName())
+	impls := inspector.Implementers(b.strcts, iface)
+	b.listedIfaces, b.listedStrcts = nil, make([]inspector.Struct, 0, len(impls))
+	if len(impls) == 0 {
+		fmt.Fprintln(b.w, "no implementers")
+	}
+	for i, impl := range impls {
+		b.listedStrcts = append(b.listedStrcts, impl.Struct)
+		fmt.Fprintf(b.w, "%3d %s\n", i+1, impl)
+	}
+
+	if nearMisses := inspector.FindNearMisses(b.strcts, iface); len(nearMisses) > 0 {
+		fmt.Fprintln(b.w, "near misses:")
+		printNearMisses(b.w, nearMisses)
+	}
+}
+
+// showStruct lists the interfaces strct implements, which can be opened next.
+func (b *repl) showStruct(strct inspector.Struct) {
+	fmt.Fprintf(b.w, "%s\n", strct)
+	satisfied := inspector.SatisfiedInterfaces(strct, b.ifaces)
+	b.listedIfaces, b.listedStrcts = make([]inspector.Interface, 0, len(satisfied)), nil
+	if len(satisfied) == 0 {
+		fmt.Fprintln(b.w, "implements no interface")
+	}
+	for i, s := range satisfied {
+		b.listedIfaces = append(b.listedIfaces, s.Interface)
+		fmt.Fprintf(b.w, "%3d %s.%s (%s receiver) %s:%d\n", i+1, s.Pkg.Name(), s.Name, s.Receiver, s.Position.Filename, s.Position.Line)
+	}
+}
+
+// isSubsequence reports whether the characters of s appear in t in the same order.
+func isSubsequence(s, t string) bool {
+	for _, r := range t {
+		if len(s) == 0 {
+			break
+		}
+		if strings.HasPrefix(s, string(r)) {
+			s = s[len(string(r)):]
+		}
+	}
+	return len(s) == 0
+}
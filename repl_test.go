@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+func TestREPL(t *testing.T) {
+	pkgs := loadSelfTest(t)
+	ifaces := inspector.FindInterfaces(pkgs)
+	// a search, opening an interface found, then one of its implementers, mistakes, and q, after which nothing is read
+	script := "?\npolygon\n1\n1\n9\nzzz\nrc\n\nq\npolygon\n"
+	var out bytes.Buffer
+	if err := runREPL(strings.NewReader(script), &out, ifaces, inspector.FindStructs(pkgs, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	root := filepath.Dir(filepath.Dir(ifaces[0].Position.Filename)) + string(filepath.Separator)
+	want := `4 interfaces, 11 types. ? for help
+> ` + replHelp + `
+>   1 shapes.Polygon shapes/shapes.go:9
+> selftest/shapes.Polygon
+  1 square (pointer receiver) impl/impl.go:9:6
+  2 rect (value receiver) impl/impl.go:20:6
+  3 cube (value receiver) impl/impl.go:61:6
+near misses:
+circle impl/impl.go:4:6
+	missing Corners: func() int
+base impl/impl.go:15:6
+	missing Corners: func() int
+triangle impl/impl.go:27:6
+	missing Area: func() float64
+	wrong signature Corners: have func() int64, want func() int
+areaFunc impl/impl.go:47:6
+	missing Corners: func() int
+> square impl/impl.go:9:6
+  1 shapes.Shape (pointer receiver) shapes/shapes.go:5
+  2 shapes.Polygon (pointer receiver) shapes/shapes.go:9
+> no result 9 in the last list
+> no interface matches "zzz"
+>   1 shapes.ReadCloser shapes/shapes.go:14
+> > `
+	if got := strings.ReplaceAll(out.String(), root, ""); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
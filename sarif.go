@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// finding is a problem reported by -format sarif.
+type finding struct {
+	RuleID   string
+	Level    string
+	Message  string
+	Position token.Position
+}
+
+// sarifRules are the rules of the findings, with their descriptions.
+var sarifRules = []struct{ ID, Description string }{
+	{"no-implementers", "No scanned type implements the interface"},
+	{"near-miss", "The type has some but not all methods of the interface"},
+	{"assertion-failed", "The type doesn't implement the interface it is asserted to implement"},
+	{"dead-interface", "The interface has no implementers or isn't used"},
+	{"unapproved-implementer", "The type implements the interface but isn't in the -allowlist"},
+}
+
+// queryFindings reports an interface without implementers and the near misses of iface.
+func queryFindings(impls []inspector.Implementer, nearMisses []inspector.NearMiss, iface inspector.Interface) []finding {
+	findings := make([]finding, 0)
+	name := iface.Pkg.Name() + "." + iface.Name
+	if len(impls) == 0 {
+		findings = append(findings, finding{
+			RuleID:   "no-implementers",
+			Level:    "error",
+			Message:  fmt.Sprintf("no scanned type implements %s", name),
+			Position: iface.Position,
+		})
+	}
+	for _, nm := range nearMisses {
+		findings = append(findings, finding{
+			RuleID:   "near-miss",
+			Level:    "warning",
+			Message:  fmt.Sprintf("%s doesn't implement %s: %s", nm.Name, name, missingSummary(nm.Missing)),
+			Position: nm.Position,
+		})
+	}
+	return findings
+}
+
+// unapprovedFindings reports the implementers of iface missing from the -allowlist.
+func unapprovedFindings(unapproved []inspector.Implementer, iface inspector.Interface) []finding {
+	findings := make([]finding, 0, len(unapproved))
+	for _, impl := range unapproved {
+		findings = append(findings, finding{
+			RuleID:   "unapproved-implementer",
+			Level:    "error",
+			Message:  fmt.Sprintf("%s implements %s.%s but isn't in the allowlist", impl.Name, iface.Pkg.Name(), iface.Name),
+			Position: impl.Position,
+		})
+	}
+	return findings
+}
+
+// assertionFindings checks the assertions like checkAssertions and reports the ones that don't hold.
+func assertionFindings(pkgs []*packages.Package, assertions []inspector.Assertion) ([]finding, error) {
+	findings := make([]finding, 0)
+	for _, a := range assertions {
+		e, err := inspector.CheckAssertion(pkgs, a)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", a, err)
+		}
+		if e.Receiver != "" {
+			continue
+		}
+		findings = append(findings, finding{
+			RuleID:   "assertion-failed",
+			Level:    "error",
+			Message:  fmt.Sprintf("%s doesn't implement %s: %s", e.Name, a.Interface, missingSummary(e.Missing)),
+			Position: e.Position,
+		})
+	}
+	return findings, nil
+}
+
+// deadInterfaceFindings reports the interfaces of dead, see printDeadInterfaces.
+func deadInterfaceFindings(dead []inspector.DeadInterface) []finding {
+	findings := make([]finding, 0, len(dead))
+	for _, d := range dead {
+		reasons := make([]string, 0, 2)
+		if d.Implementers == 0 {
+			reasons = append(reasons, "has no implementers")
+		}
+		if d.Usages == 0 {
+			reasons = append(reasons, "is unused")
+		}
+		findings = append(findings, finding{
+			RuleID:   "dead-interface",
+			Level:    "warning",
+			Message:  fmt.Sprintf("%s.%s %s", d.Pkg.Name(), d.Name, strings.Join(reasons, " and ")),
+			Position: d.Position,
+		})
+	}
+	return findings
+}
+
+// missingSummary lists the methods of missing in a line, like "missing Close, wrong signature Fetch".
+// A pointer receiver is needed if nothing is missing.
+func missingSummary(missing []inspector.MethodMatch) string {
+	if len(missing) == 0 {
+		return "only the pointer implements it"
+	}
+	problems := make([]string, 0, len(missing))
+	for _, m := range missing {
+		if m.WrongSignature {
+			problems = append(problems, "wrong signature "+m.Method.Name())
+		} else {
+			problems = append(problems, "missing "+m.Method.Name())
+		}
+	}
+	return strings.Join(problems, ", ")
+}
+
+// printSARIF writes findings as a SARIF 2.1.0 log, as uploaded to GitHub code scanning. The files under dir are
+// referenced relative to it, the root of the sources, and the others by their absolute path.
+func printSARIF(w io.Writer, findings []finding, dir string) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	type artifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+	type region struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+	type physicalLocation struct {
+		ArtifactLocation artifactLocation `json:"artifactLocation"`
+		Region           region           `json:"region"`
+	}
+	type location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	rules := make([]rule, 0, len(sarifRules))
+	for _, r := range sarifRules {
+		rules = append(rules, rule{ID: r.ID, ShortDescription: message{r.Description}})
+	}
+	results := make([]result, 0, len(findings))
+	for _, f := range findings {
+		loc := artifactLocation{URI: "file://" + filepath.ToSlash(f.Position.Filename)}
+		if rel, err := filepath.Rel(absDir, f.Position.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+			loc = artifactLocation{URI: filepath.ToSlash(rel), URIBaseID: "%SRCROOT%"}
+		}
+		results = append(results, result{
+			RuleID:  f.RuleID,
+			Level:   f.Level,
+			Message: message{f.Message},
+			Locations: []location{{PhysicalLocation: physicalLocation{
+				ArtifactLocation: loc,
+				Region:           region{StartLine: f.Position.Line, StartColumn: f.Position.Column},
+			}}},
+		})
+	}
+
+	type driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+		Rules          []rule `json:"rules"`
+	}
+	type tool struct {
+		Driver driver `json:"driver"`
+	}
+	type run struct {
+		Tool    tool     `json:"tool"`
+		Results []result `json:"results"`
+	}
+	log := struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []run  `json:"runs"`
+	}{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []run{{
+			Tool:    tool{Driver: driver{Name: "interface-inspector", InformationURI: "https://github.com/magdyamr542/interface-inspector", Rules: rules}},
+			Results: results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrintSARIF(t *testing.T) {
+	root := t.TempDir()
+	findings := []finding{
+		{RuleID: "no-implementers", Level: "error", Message: "no scanned type implements store.Store", Position: token.Position{Filename: filepath.Join(root, "store", "store.go"), Line: 5, Column: 6}},
+		{RuleID: "near-miss", Level: "warning", Message: "PG doesn't implement store.Store: missing Close", Position: token.Position{Filename: filepath.Join(root, "db", "pg.go"), Line: 12, Column: 6}},
+	}
+	var buf bytes.Buffer
+	if err := printSARIF(&buf, findings, root); err != nil {
+		t.Fatal(err)
+	}
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "sarif.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Errorf("got\n%s\nwant\n%s", buf.Bytes(), golden)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct{ Name string }
+			}
+			Results []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string
+							URIBaseID string
+						}
+						Region struct{ StartLine, StartColumn int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" {
+		t.Errorf("got version %q, want 2.1.0", log.Version)
+	}
+	if len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "interface-inspector" {
+		t.Fatalf("got runs %+v, want one of interface-inspector", log.Runs)
+	}
+	// the locations are relative to the root, as code scanning resolves them against the checkout
+	want := []string{"store/store.go:5:6", "db/pg.go:12:6"}
+	if len(log.Runs[0].Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(log.Runs[0].Results), len(want))
+	}
+	for i, result := range log.Runs[0].Results {
+		if len(result.Locations) != 1 {
+			t.Fatalf("result %d: got %d locations, want 1", i, len(result.Locations))
+		}
+		loc := result.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+			t.Errorf("result %d: got uriBaseId %q, want %%SRCROOT%%", i, loc.ArtifactLocation.URIBaseID)
+		}
+		if got := fmt.Sprintf("%s:%d:%d", loc.ArtifactLocation.URI, loc.Region.StartLine, loc.Region.StartColumn); got != want[i] {
+			t.Errorf("result %d: got location %s, want %s", i, got, want[i])
+		}
+	}
+}
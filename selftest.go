@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// selfTestFixtures is a small module with known implementers, described by its cases.txt.
+//
+//go:embed testdata/selftest
+var selfTestFixtures embed.FS
+
+const selfTestRoot = "testdata/selftest"
+
+// selfTestCase is one line of cases.txt.
+type selfTestCase struct {
+	packageDir    string
+	packageName   string
+	interfaceName string
+	expected      []string
+}
+
+// runSelfTest runs the inspector against the embedded fixtures and reports every case to w.
+// It returns false if a case failed.
+func runSelfTest(w io.Writer) (bool, error) {
+	dir, err := os.MkdirTemp("", "interface-inspector-selftest")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeSelfTestModule(dir); err != nil {
+		return false, fmt.Errorf("write fixtures: %w", err)
+	}
+
+	cases, err := readSelfTestCases()
+	if err != nil {
+		return false, fmt.Errorf("read cases: %w", err)
+	}
+
+	pkgs, err := inspector.Load(context.Background(), inspector.Query{Dir: dir})
+	if err != nil {
+		return false, fmt.Errorf("load fixtures: %w", err)
+	}
+
+	passed := true
+	for _, c := range cases {
+		name := c.packageName + "." + c.interfaceName
+		iface, err := inspector.FindInterface(pkgs, c.packageName, c.packageDir, c.interfaceName)
+		if err != nil {
+			fmt.Fprintf(w, "FAIL %s: %v\n", name, err)
+			passed = false
+			continue
+		}
+
+		got := make([]string, 0)
+		for _, strct := range inspector.Implementers(inspector.FindStructs(pkgs, 0), iface) {
+			got = append(got, strct.Name)
+		}
+		sort.Strings(got)
+
+		if strings.Join(got, " ") != strings.Join(c.expected, " ") {
+			fmt.Fprintf(w, "FAIL %s: got %v, want %v\n", name, got, c.expected)
+			passed = false
+			continue
+		}
+		fmt.Fprintf(w, "PASS %s\n", name)
+	}
+
+	return passed, nil
+}
+
+// writeSelfTestModule extracts the fixtures into dir and makes it a module.
+// The go.mod file is written here because an embedded directory can't contain one.
+func writeSelfTestModule(dir string) error {
+	err := fs.WalkDir(selfTestFixtures, selfTestRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(selfTestRoot, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := selfTestFixtures.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module selftest\n\ngo 1.19\n"), 0o644)
+}
+
+func readSelfTestCases() ([]selfTestCase, error) {
+	data, err := selfTestFixtures.ReadFile(selfTestRoot + "/cases.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]selfTestCase, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		query, expected, ok := strings.Cut(line, ":")
+		fields := strings.Fields(query)
+		if !ok || len(fields) != 3 {
+			return nil, fmt.Errorf("malformed case %q", line)
+		}
+		want := strings.Fields(expected)
+		sort.Strings(want)
+		cases = append(cases, selfTestCase{packageDir: fields[0], packageName: fields[1], interfaceName: fields[2], expected: want})
+	}
+	return cases, scanner.Err()
+}
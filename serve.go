@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// server answers the queries of -serve from the packages kept loaded by a session.
+type server struct {
+	session *inspector.Session
+	// scanPackages selects the packages whose types are scanned among the loaded ones
+	scanPackages func(pkgs []*packages.Package) []*packages.Package
+	jobs         int
+}
+
+// explanationJSON tells why a type does or doesn't implement an interface, see inspector.Explain.
+type explanationJSON struct {
+	Implements bool `json:"implements"`
+	// Receiver is "value" or "pointer" if the type implements the interface
+	Receiver    string       `json:"receiver,omitempty"`
+	Missing     []methodJSON `json:"missing"`
+	PointerOnly []string     `json:"pointer_only"`
+}
+
+// serve answers queries over HTTP on addr until it fails:
+//
+//	GET /implementers?interface=REF	the implementers of the interface, like -format json
+//	GET /interfaces?type=REF		the interfaces the type implements, like -struct with -format json
+//	GET /near-misses?interface=REF	the near misses of the interface, like -near-miss-json
+//	GET /explain?type=REF&interface=REF	why the type does or doesn't implement the interface, like -why
+//
+// The references are qualified like github.com/me/proj/pkg/db.Store. The import paths of types may be shortened.
+func serve(addr string, s *server) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /implementers", s.implementers)
+	mux.HandleFunc("GET /interfaces", s.interfaces)
+	mux.HandleFunc("GET /near-misses", s.nearMisses)
+	mux.HandleFunc("GET /explain", s.explain)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (s *server) implementers(w http.ResponseWriter, r *http.Request) {
+	pkgs, ok := s.packages(w, r)
+	if !ok {
+		return
+	}
+	iface, ok := s.lookupInterface(w, r, pkgs)
+	if !ok {
+		return
+	}
+	strcts := inspector.FindStructs(s.scanPackages(pkgs), s.jobs)
+	writeJSON(w, http.StatusOK, implementersToJSON(inspector.ParallelImplementers(strcts, iface, s.jobs), iface))
+}
+
+func (s *server) interfaces(w http.ResponseWriter, r *http.Request) {
+	pkgs, ok := s.packages(w, r)
+	if !ok {
+		return
+	}
+	strct, ok := s.lookupType(w, r, pkgs)
+	if !ok {
+		return
+	}
+	satisfied := inspector.SatisfiedInterfaces(strct, inspector.FindInterfaces(s.scanPackages(pkgs)))
+	writeJSON(w, http.StatusOK, satisfiedToJSON(strct, satisfied))
+}
+
+func (s *server) nearMisses(w http.ResponseWriter, r *http.Request) {
+	pkgs, ok := s.packages(w, r)
+	if !ok {
+		return
+	}
+	iface, ok := s.lookupInterface(w, r, pkgs)
+	if !ok {
+		return
+	}
+	strcts := inspector.FindStructs(s.scanPackages(pkgs), s.jobs)
+	writeJSON(w, http.StatusOK, nearMissesToJSON(inspector.FindNearMisses(strcts, iface)))
+}
+
+func (s *server) explain(w http.ResponseWriter, r *http.Request) {
+	// both are looked up in the same packages, the session may reload them in between otherwise
+	pkgs, ok := s.packages(w, r)
+	if !ok {
+		return
+	}
+	iface, ok := s.lookupInterface(w, r, pkgs)
+	if !ok {
+		return
+	}
+	strct, ok := s.lookupType(w, r, pkgs)
+	if !ok {
+		return
+	}
+
+	e := inspector.Explain(strct, iface)
+	result := explanationJSON{
+		Implements:  e.Receiver != "",
+		Receiver:    string(e.Receiver),
+		Missing:     methodsToJSON(e.Missing),
+		PointerOnly: make([]string, 0, len(e.PointerOnly)),
+	}
+	for _, fn := range e.PointerOnly {
+		result.PointerOnly = append(result.PointerOnly, fn.Name())
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// packages returns the packages of the session, reloaded if they changed. It writes the error and returns false
+// if it can't.
+func (s *server) packages(w http.ResponseWriter, r *http.Request) ([]*packages.Package, bool) {
+	pkgs, err := s.session.Packages(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return pkgs, true
+}
+
+// lookupInterface finds the interface of the interface parameter of r in pkgs. It writes the error and returns
+// false if it can't.
+func (s *server) lookupInterface(w http.ResponseWriter, r *http.Request, pkgs []*packages.Package) (inspector.Interface, bool) {
+	ref := r.URL.Query().Get("interface")
+	if ref == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing the interface parameter"))
+		return inspector.Interface{}, false
+	}
+	iface, err := inspector.FindInterfaceByRef(pkgs, ref)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return inspector.Interface{}, false
+	}
+	return iface, true
+}
+
+// lookupType finds the type of the type parameter of r in pkgs. It writes the error and returns false if it can't.
+func (s *server) lookupType(w http.ResponseWriter, r *http.Request, pkgs []*packages.Package) (inspector.Struct, bool) {
+	ref := r.URL.Query().Get("type")
+	if ref == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing the type parameter"))
+		return inspector.Struct{}, false
+	}
+	strct, err := inspector.FindStructByRef(pkgs, ref)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return inspector.Struct{}, false
+	}
+	return strct, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
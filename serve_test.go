@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+func TestServeExplain(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeSelfTestModule(dir); err != nil {
+		t.Fatal(err)
+	}
+	s := &server{
+		session:      inspector.NewSession(inspector.Query{Dir: dir}),
+		scanPackages: func(pkgs []*packages.Package) []*packages.Package { return pkgs },
+		jobs:         1,
+	}
+
+	for _, tt := range []struct {
+		query  string
+		status int
+		want   explanationJSON
+	}{
+		{"type=selftest/impl.circle&interface=selftest/shapes.Shape", http.StatusOK, explanationJSON{Implements: true, Receiver: "value"}},
+		{"type=selftest/impl.circle&interface=selftest/shapes.Polygon", http.StatusOK, explanationJSON{}},
+		{"type=selftest/impl.circle", http.StatusBadRequest, explanationJSON{}},
+		{"type=selftest/impl.missing&interface=selftest/shapes.Shape", http.StatusNotFound, explanationJSON{}},
+	} {
+		rec := httptest.NewRecorder()
+		s.explain(rec, httptest.NewRequest(http.MethodGet, "/explain?"+tt.query, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: got status %d, want %d: %s", tt.query, rec.Code, tt.status, rec.Body)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var got explanationJSON
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Implements != tt.want.Implements || got.Receiver != tt.want.Receiver {
+			t.Errorf("%s: got %+v, want %+v", tt.query, got, tt.want)
+		}
+		if !got.Implements && len(got.Missing) == 0 {
+			t.Errorf("%s: no missing methods", tt.query)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// writeStubs appends the stubs to the Go file filename and adds the imports they need.
+func writeStubs(filename string, stubs inspector.Stubs) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	for _, path := range stubs.Imports {
+		astutil.AddImport(fset, f, path)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	buf.Write(stubs.Source)
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, info.Mode())
+}
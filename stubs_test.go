@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+func TestWriteStubs(t *testing.T) {
+	pkgs := loadModule(t, map[string]string{
+		"store/store.go": `package store
+
+import "context"
+
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Put(key, value string) error
+	Len() int
+}
+`,
+		"db/db.go": `package db
+
+// PG has a method of the interface with a wrong signature.
+type PG struct{}
+
+func (p *PG) Len() string { return "" }
+
+// Mem has none of the methods.
+type Mem struct{}
+`,
+	})
+	iface, err := inspector.FindInterfaceByRef(pkgs, "example.com/m/store.Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := ""
+	for _, name := range []string{"PG", "Mem"} {
+		strct, err := inspector.FindStructByRef(pkgs, "example.com/m/db."+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stubs, err := inspector.GenerateStubs(strct, iface)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename = strct.Position.Filename
+		if err := writeStubs(filename, stubs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if formatted, err := format.Source(src); err != nil || !bytes.Equal(formatted, src) {
+		t.Errorf("the file isn't formatted:\n%s", src)
+	}
+	// the method with the wrong signature is left as it is
+	if n := strings.Count(string(src), "func (p *PG) Len("); n != 1 {
+		t.Errorf("got %d methods Len of PG, want 1:\n%s", n, src)
+	}
+	if !strings.Contains(string(src), "func (p *PG) Len() string { return \"\" }") {
+		t.Errorf("the method Len of PG was changed:\n%s", src)
+	}
+
+	// the stubs compile, the ones of Mem are all it needs to implement the interface
+	dir := filepath.Dir(filepath.Dir(filename))
+	check := "package check\n\nimport (\n\t\"example.com/m/db\"\n\t\"example.com/m/store\"\n)\n\nvar _ store.Store = (*db.Mem)(nil)\n"
+	if err := os.MkdirAll(filepath.Join(dir, "check"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "check", "check.go"), []byte(check), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pkgs = load(t, inspector.Query{Dir: dir})
+
+	strct, err := inspector.FindStructByRef(pkgs, "example.com/m/db.PG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface, err = inspector.FindInterfaceByRef(pkgs, "example.com/m/store.Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := inspector.Explain(strct, iface).Missing
+	if len(missing) != 1 || missing[0].Method.Name() != "Len" || !missing[0].WrongSignature {
+		t.Errorf("got missing methods %v of PG, want only Len with a wrong signature", missing)
+	}
+}
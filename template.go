@@ -0,0 +1,84 @@
+package main
+
+import (
+	"go/types"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// templateData is what a -format template is executed with, once per implementer.
+type templateData struct {
+	// Name is the name of the type, with its type parameters if it's generic, like lru[K, V].
+	Name string
+	// Package is the import path of the package of the type and PackageName its name.
+	Package     string
+	PackageName string
+	// Kind is struct for structs and describes the underlying type otherwise, like func or slice.
+	Kind string
+	// Receiver is value if the value implements the interface and pointer if only a pointer to it does.
+	Receiver string
+	// Position is file:line:column, also given as Filename, Line and Column.
+	Position string
+	Filename string
+	Line     int
+	Column   int
+	// Interface is the qualified name of the implemented interface.
+	Interface string
+	// Methods are the methods of the interface.
+	Methods []templateMethod
+}
+
+type templateMethod struct {
+	Name string
+	// Signature is the signature without func, like (url string) ([]byte, error).
+	Signature string
+}
+
+// isTemplate reports whether the -format value is a template rather than the name of a format.
+func isTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// parseTemplate parses the template of -format. A newline is added after every result unless the template
+// ends with one.
+func parseTemplate(format string) (*template.Template, error) {
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	return template.New("format").Parse(format)
+}
+
+// printTemplate executes tmpl for every implementer of iface.
+func printTemplate(w io.Writer, tmpl *template.Template, strcts []inspector.Implementer, iface inspector.Interface) error {
+	methods := make([]templateMethod, 0, iface.Type.NumMethods())
+	for i := 0; i < iface.Type.NumMethods(); i++ {
+		m := iface.Type.Method(i)
+		methods = append(methods, templateMethod{
+			Name:      m.Name(),
+			Signature: strings.TrimPrefix(types.TypeString(m.Type(), inspector.PackageNameQualifier), "func"),
+		})
+	}
+
+	for _, strct := range strcts {
+		data := templateData{
+			Name:        strct.Name,
+			Package:     strct.PkgPath,
+			PackageName: strct.Obj.Pkg().Name(),
+			Kind:        strct.Kind,
+			Receiver:    string(strct.Receiver),
+			Position:    strct.PositionString(),
+			Filename:    strct.Position.Filename,
+			Line:        strct.Position.Line,
+			Column:      strct.Position.Column,
+			Interface:   iface.QualifiedName(),
+			Methods:     methods,
+		}
+		if err := tmpl.Execute(w, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+func TestPrintTemplate(t *testing.T) {
+	pkgs := loadSelfTest(t)
+	iface, err := inspector.FindInterfaceByRef(pkgs, "selftest/shapes.Shape")
+	if err != nil {
+		t.Fatal(err)
+	}
+	circle := findStruct(t, inspector.FindStructs(pkgs, 0), "circle")
+	impls := []inspector.Implementer{{Struct: circle, Receiver: inspector.ValueReceiver}}
+
+	tmpl, err := parseTemplate("{{.Name}}|{{.Package}}|{{.PackageName}}|{{.Kind}}|{{.Receiver}}|{{.Position}}|" +
+		"{{.Filename}}|{{.Line}}|{{.Column}}|{{.Interface}}|{{range .Methods}}{{.Name}}{{.Signature}}{{end}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := printTemplate(&buf, tmpl, impls, iface); err != nil {
+		t.Fatal(err)
+	}
+	file := circle.Position.Filename
+	want := fmt.Sprintf("circle|selftest/impl|impl|struct|value|%s:4:6|%s|4|6|selftest/shapes.Shape|Area() float64\n", file, file)
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeSelfTestModule(dir); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, tt := range []struct {
+		format string
+		want   string
+	}{
+		// the template is parsed before anything is loaded
+		{"{{.Name", "error: -format: template: format:2: unclosed action started at format:1\n"},
+		// a field that doesn't exist is only found when the template is executed
+		{"{{.Nope}}", "error: -format: template: format:1:2: executing \"format\" at <.Nope>: can't evaluate field Nope in type main.templateData\n"},
+	} {
+		out, code := runMain(t, "-dir", filepath.Join(dir, "impl"), "-interface", "selftest/shapes.Shape", "-format", tt.format)
+		if code != 1 {
+			t.Errorf("-format %q: got exit code %d, want 1", tt.format, code)
+		}
+		if !strings.HasSuffix(out, tt.want) {
+			t.Errorf("-format %q: got output %q, want it to end with %q", tt.format, out, tt.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package main
+
+import "os"
+
+// terminalWidth isn't supported on this platform, so output is never truncated by default.
+func terminalWidth(f *os.File) (width int, ok bool) {
+	return 0, false
+}
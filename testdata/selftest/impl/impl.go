@@ -0,0 +1,65 @@
+package impl
+
+// circle implements Shape with a value receiver.
+type circle struct{ r float64 }
+
+func (c circle) Area() float64 { return 3.14 * c.r * c.r }
+
+// square implements Polygon with pointer receivers.
+type square struct{ a float64 }
+
+func (s *square) Area() float64 { return s.a * s.a }
+func (s *square) Corners() int  { return 4 }
+
+// base provides Area to the structs embedding it.
+type base struct{}
+
+func (base) Area() float64 { return 0 }
+
+// rect implements Polygon through the embedded base.
+type rect struct {
+	base
+}
+
+func (rect) Corners() int { return 4 }
+
+// triangle has a Corners method with the wrong signature.
+type triangle struct{}
+
+func (triangle) Corners() int64 { return 3 }
+
+// file implements ReadCloser.
+type file struct{}
+
+func (*file) Read(p []byte) (int, error) { return 0, nil }
+func (*file) Close() error               { return nil }
+
+// Aliases, named pointer types and variables must not be reported in addition to the types they refer to.
+type circleAlias = circle
+
+type squarePtr = *square
+
+type namedSquarePtr *square
+
+var defaultCircle = circle{}
+
+// areaFunc is a function type implementing Shape.
+type areaFunc func() float64
+
+func (f areaFunc) Area() float64 { return f() }
+
+// box implements Getter[T] for every T, intBox only Getter[int].
+type box[T any] struct{ v T }
+
+func (b box[T]) Get() T { return b.v }
+
+type intBox struct{}
+
+func (intBox) Get() int { return 0 }
+
+// cube implements Solid with Area promoted through rect and base, two levels down.
+type cube struct {
+	rect
+}
+
+func (cube) Volume() float64 { return 0 }
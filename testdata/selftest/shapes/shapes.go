@@ -0,0 +1,27 @@
+package shapes
+
+import "io"
+
+type Shape interface {
+	Area() float64
+}
+
+type Polygon interface {
+	Shape
+	Corners() int
+}
+
+type ReadCloser interface {
+	io.Reader
+	Close() error
+}
+
+type Getter[T any] interface {
+	Get() T
+}
+
+// Solid embeds Polygon, which embeds Shape in turn.
+type Solid interface {
+	Polygon
+	Volume() float64
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/magdyamr542/interface-inspector/inspector"
+)
+
+// watchSettle is how long -watch waits after a change for the ones coming with it, like the several
+// files a save or a checkout writes, before running the query again.
+const watchSettle = 100 * time.Millisecond
+
+// fileState is what a change of a file is detected by.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watch calls run with the packages of session every time a .go file under dir is added, removed or changed.
+// When files were only changed, the session type checks their packages again and keeps the others, see
+// inspector.Session. It returns only on errors.
+func watch(w io.Writer, dir string, session *inspector.Session, run func([]*packages.Package) int) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watchDirs(watcher, dir); err != nil {
+		return err
+	}
+
+	var last map[string]fileState
+	for {
+		current, err := goFiles(dir)
+		if err != nil {
+			return err
+		}
+		// the events only tell that something changed, like a file an editor writes next to the .go ones
+		if !sameFiles(last, current) {
+			var pkgs []*packages.Package
+			// a new file may be in a package none of the loaded ones imports yet
+			if last != nil && !samePaths(last, current) {
+				pkgs, err = session.Reload(context.Background())
+			} else {
+				pkgs, err = session.Packages(context.Background())
+			}
+			last = current
+			fmt.Fprintf(w, "--- %s\n", time.Now().Format(time.TimeOnly))
+			// a failed run, like code that doesn't compile yet, is part of the output and doesn't stop watching
+			if err != nil {
+				fmt.Fprintf(w, "error: %v\n", err)
+			} else {
+				run(pkgs)
+			}
+		}
+		if err := nextChange(watcher); err != nil {
+			return err
+		}
+	}
+}
+
+// nextChange waits for a change under the directories of watcher, and for watchSettle after the last one.
+// It watches the directories created in the meantime too.
+func nextChange(watcher *fsnotify.Watcher) error {
+	var settled <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return errors.New("stopped watching")
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchDirs(watcher, event.Name); err != nil {
+						return err
+					}
+				}
+			}
+			settled = time.After(watchSettle)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return errors.New("stopped watching")
+			}
+			return err
+		case <-settled:
+			return nil
+		}
+	}
+}
+
+// watchDirs adds dir and the directories under it to watcher, but the ones goFiles skips.
+func watchDirs(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		// removed since it was listed, there is nothing to watch anymore
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// goFiles returns the state of every .go file under dir. Like the go command, it skips the directories
+// named testdata or starting with . or _.
+func goFiles(dir string) (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		// removed since it was listed, like the file an editor saves by renaming another over it
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != dir && skipDir(name) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files, err
+}
+
+// skipDir reports whether the go command skips the directories named name.
+func skipDir(name string) bool {
+	return name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func sameFiles(a, b map[string]fileState) bool {
+	if a == nil || !samePaths(a, b) {
+		return false
+	}
+	for path, state := range a {
+		if b[path] != state {
+			return false
+		}
+	}
+	return true
+}
+
+// samePaths reports whether a and b have the same files, whatever their state.
+func samePaths(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path := range a {
+		if _, ok := b[path]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.work":            "go 1.22\n\nuse (\n\t./a\n\t./b\n)\n",
+		"a/go.mod":           "module example.com/a\n\ngo 1.22\n",
+		"a/fetch/fetch.go":   "package fetch\n\ntype Fetcher interface{ Fetch() }\n\ntype Local struct{}\n\nfunc (Local) Fetch() {}\n",
+		"b/go.mod":           "module example.com/b\n\ngo 1.22\n\nrequire example.com/a v0.0.0\n",
+		"b/remote/remote.go": "package remote\n\ntype Remote struct{}\n\nfunc (*Remote) Fetch() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// -mod=mod, like other flags about the go.mod file, can't be used in a workspace
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, tt := range []struct {
+		module string
+		want   []string
+	}{
+		// the implementers of every module of the workspace, wherever the interface is
+		{"", []string{"Local", "Remote"}},
+		{"example.com/a", []string{"Local"}},
+		{"example.com/b", []string{"Remote"}},
+	} {
+		out, code := runMain(t, "-dir", dir, "-interface", "example.com/a/fetch.Fetcher", "-module", tt.module, "-format", "{{.Name}}")
+		if code != 0 {
+			t.Fatalf("-module %q: got exit code %d: %s", tt.module, code, out)
+		}
+		got := strings.Fields(out)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("-module %q: got %v, want %v", tt.module, got, tt.want)
+		}
+	}
+}